@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
+	"github.com/normalniydada/test_task_infotecs/internal/seeds"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"github.com/normalniydada/test_task_infotecs/internal/storage"
+	"go.uber.org/zap"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// runMigrate применяет миграции базы данных и завершает работу (подкоманда `migrate`)
+//
+// Миграции выполняет storage.InitDB, поэтому схема совпадает со схемой, которую создаёт сервер при запуске
+func runMigrate() {
+	cfg, zLog := load()
+	db := storage.InitDB(&cfg.Database, zLog)
+	storage.CloseDB(db, zLog)
+}
+
+// runSeed создаёт начальные данные и завершает работу (подкоманда `seed`)
+//
+// Кошельки создаются по настройкам seed независимо от seed.enabled: параметр отключает только заполнение при запуске сервера
+func runSeed() {
+	cfg, zLog := load()
+	db := storage.InitDB(&cfg.Database, zLog)
+	seeds.InitWallets(db, &cfg.Seed, zLog)
+	seeds.InitAdminKey(db, cfg.Auth.BootstrapAdminKey, zLog)
+	storage.CloseDB(db, zLog)
+}
+
+// runReconcile сверяет балансы кошельков с историей операций (подкоманда `reconcile [-json]`)
+//
+// Возвращает код завершения: 0 - расхождений нет, 1 - найдены расхождения или произошла ошибка
+func runReconcile(args []string) int {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, zLog := load()
+	db := storage.InitDB(&cfg.Database, zLog)
+	defer storage.CloseDB(db, zLog)
+
+	report, err := services.Reconcile(cliContext(), db)
+	if err != nil {
+		zLog.Error("Reconcile failed", zap.Error(err))
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		fmt.Printf("wallets: %d, transactions: %d, total balance: %s\n",
+			report.Wallets, report.Transactions, dto.Amount(report.TotalBalance))
+		if len(report.Issues) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ADDRESS\tPROBLEM\tBALANCE\tEXPECTED")
+			for _, issue := range report.Issues {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", issue.Address, issue.Problem, dto.Amount(issue.Balance), dto.Amount(issue.Expected))
+			}
+			_ = w.Flush()
+		}
+	}
+
+	if len(report.Issues) > 0 {
+		zLog.Warn("Reconcile found discrepancies", zap.Int("issues", len(report.Issues)))
+		return 1
+	}
+	return 0
+}
+
+// runWalletAdjust выполняет ручную корректировку баланса кошелька (подкоманда `wallet adjust`)
+//
+// Сумма задаётся строкой в у.е. со знаком: "25.00" - начисление, "-25.00" - списание.
+// Корректировка проводится транзакцией с кошельком капитала (см. services.AdjustBalance).
+// Возвращает код завершения: 0 - баланс изменён, 1 - операция отклонена или произошла ошибка
+func runWalletAdjust(args []string) int {
+	fs := flag.NewFlagSet("wallet adjust", flag.ContinueOnError)
+	address := fs.String("address", "", "wallet address")
+	amount := fs.String("amount", "", "amount to credit, negative to debit, e.g. -12.50")
+	reasonCode := fs.String("reason-code", "", "reason code: "+strings.Join(models.AdjustmentReasonCodes, ", "))
+	note := fs.String("note", "", "free-text note, stored with the transaction")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	delta, err := dto.ParseAmount(*amount)
+	if *address == "" || err != nil {
+		fmt.Fprintln(os.Stderr, "wallet adjust requires -address and -amount with at most 2 fractional digits")
+		return 2
+	}
+
+	cfg, zLog := load()
+	db := storage.InitDB(&cfg.Database, zLog)
+	defer storage.CloseDB(db, zLog)
+
+	ctx := cliContext()
+	transaction, err := services.AdjustBalance(ctx, db, *address, int64(delta), *reasonCode, *note)
+	if err != nil {
+		zLog.Error("Wallet adjustment rejected", zap.String("address", *address), zap.Error(err))
+		fmt.Fprintf(os.Stderr, "error: %v (%s)\n", err, services.ErrorCode(err))
+		return 1
+	}
+
+	balance, err := services.GetWalletBalance(ctx, db, *address)
+	if err != nil {
+		zLog.Error("Get balance failed", zap.String("address", *address), zap.Error(err))
+		return 1
+	}
+	fmt.Printf("transaction %d: %s adjusted by %s, balance %s\n", transaction.ID, *address, delta, dto.Amount(balance))
+	return 0
+}
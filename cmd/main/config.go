@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"os"
+)
+
+// runConfig обрабатывает подкоманды `config`
+//
+// Поддерживаемые подкоманды:
+//   - config check [-config path] — проверка файла конфигурации без запуска сервера
+//
+// Возвращает код завершения процесса
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, "usage: main config check [-config path]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	path := fs.String("config", config.DefaultPath, "path to config file")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err = cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: invalid config:\n%v\n", *path, err)
+		return 1
+	}
+
+	fmt.Printf("%s: config OK\n", *path)
+	return 0
+}
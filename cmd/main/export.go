@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"github.com/normalniydada/test_task_infotecs/internal/storage"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// exportBatchSize - число транзакций, читаемых из базы данных за один запрос
+const exportBatchSize = 1000
+
+// runExport выгружает транзакции или кошельки в CSV или JSON Lines
+// (подкоманда `export transactions|wallets [-format csv|json] [-since T] [-until T] [-out FILE]`)
+//
+// Суммы выгружаются строками в у.е., как в API v2. Период (-since, -until в RFC 3339) применяется к транзакциям.
+// Возвращает код завершения процесса
+func runExport(args []string) int {
+	if len(args) == 0 || (args[0] != "transactions" && args[0] != "wallets") {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	what := args[0]
+
+	fs := flag.NewFlagSet("export "+what, flag.ContinueOnError)
+	format := fs.String("format", "csv", "output format: csv or json (one JSON object per line)")
+	sinceFlag := fs.String("since", "", "export transactions created at or after this time (RFC 3339)")
+	untilFlag := fs.String("until", "", "export transactions created before this time (RFC 3339)")
+	out := fs.String("out", "", "output file (default: stdout)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		return 2
+	}
+	since, err := parseTimeFlag(*sinceFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid -since:", err)
+		return 2
+	}
+	until, err := parseTimeFlag(*untilFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid -until:", err)
+		return 2
+	}
+
+	cfg, zLog := load()
+	db := storage.InitDB(&cfg.Database, zLog)
+	defer storage.CloseDB(db, zLog)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			zLog.Error("Export failed", zap.Error(err))
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	ew := newExportWriter(w, *format)
+	ctx := cliContext()
+	switch what {
+	case "transactions":
+		err = ew.header("id", "from", "to", "amount", "created_at", "type", "reason_code", "note", "status", "error_code", "memo", "reference", "metadata")
+		if err == nil {
+			err = services.EachTransaction(ctx, db, since, until, exportBatchSize, func(batch []models.Transaction) error {
+				for i := range batch {
+					if err := ew.transaction(&batch[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	case "wallets":
+		err = ew.header("address", "balance", "public_key", "nonce", "frozen")
+		if err == nil {
+			var wallets []models.Wallet
+			if wallets, err = services.ListWallets(ctx, db); err == nil {
+				for i := range wallets {
+					if err = ew.wallet(&wallets[i]); err != nil {
+						break
+					}
+				}
+			}
+		}
+	}
+	if err == nil {
+		err = ew.flush()
+	}
+	if err != nil {
+		zLog.Error("Export failed", zap.String("data", what), zap.Error(err))
+		return 1
+	}
+	return 0
+}
+
+// parseTimeFlag разбирает время в формате RFC 3339; пустая строка - нулевое время
+func parseTimeFlag(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// exportWriter записывает строки выгрузки в CSV или JSON Lines
+type exportWriter struct {
+	csv  *csv.Writer
+	json *json.Encoder
+}
+
+// newExportWriter создаёт запись выгрузки в формате format ("csv" или "json")
+func newExportWriter(w io.Writer, format string) *exportWriter {
+	if format == "json" {
+		return &exportWriter{json: json.NewEncoder(w)}
+	}
+	return &exportWriter{csv: csv.NewWriter(w)}
+}
+
+// header записывает заголовок CSV; для JSON Lines ничего не делает
+func (e *exportWriter) header(columns ...string) error {
+	if e.csv == nil {
+		return nil
+	}
+	return e.csv.Write(columns)
+}
+
+// transaction записывает транзакцию
+func (e *exportWriter) transaction(t *models.Transaction) error {
+	if e.json != nil {
+		return e.json.Encode(dto.NewTransactionResponse(t))
+	}
+	// Метаданные записываются в колонку CSV объектом JSON
+	var metadata string
+	if len(t.Metadata) > 0 {
+		data, err := json.Marshal(t.Metadata)
+		if err != nil {
+			return err
+		}
+		metadata = string(data)
+	}
+	return e.csv.Write([]string{
+		strconv.FormatUint(uint64(t.ID), 10), t.From, t.To, dto.Amount(t.Amount).String(), t.CreatedAt.UTC().Format(time.RFC3339),
+		t.Type, t.ReasonCode, t.Note, t.Status, t.ErrorCode, t.Memo, t.Reference, metadata,
+	})
+}
+
+// wallet записывает кошелёк
+func (e *exportWriter) wallet(w *models.Wallet) error {
+	if e.json != nil {
+		return e.json.Encode(dto.WalletResponseV2{
+			Address:   w.Address,
+			Balance:   dto.Amount(w.Balance),
+			PublicKey: w.PublicKey,
+			Nonce:     w.Nonce,
+			Frozen:    w.Frozen,
+		})
+	}
+	return e.csv.Write([]string{
+		w.Address, dto.Amount(w.Balance).String(), w.PublicKey, strconv.FormatUint(w.Nonce, 10), strconv.FormatBool(w.Frozen),
+	})
+}
+
+// flush завершает запись CSV и возвращает ошибку записи
+func (e *exportWriter) flush() error {
+	if e.csv == nil {
+		return nil
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/grpcapi"
+	"github.com/normalniydada/test_task_infotecs/internal/ratelimit"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+	"net"
+)
+
+// startGRPC запускает gRPC сервер на отдельном адресе `grpc.address`
+//
+// Ошибка прослушивания адреса завершает программу сразу, ошибка обслуживания передаётся в serverErr.
+// Возвращает nil, если gRPC сервер выключен
+func startGRPC(cfg *config.Config, db *gorm.DB, jwtVerifier *auth.JWTVerifier, limiter ratelimit.Store, zLog *zap.Logger, serverErr chan<- error) *grpc.Server {
+	if !cfg.GRPC.Enabled {
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", cfg.GRPC.Address)
+	if err != nil {
+		zLog.Fatal("Error start the gRPC server", zap.Error(err))
+	}
+
+	srv := grpcapi.NewServer(db, cfg, jwtVerifier, limiter, zLog)
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			serverErr <- err
+		}
+	}()
+
+	zLog.Info("gRPC server is running...", zap.String("address", cfg.GRPC.Address))
+	return srv
+}
+
+// stopGRPC дожидается завершения текущих вызовов gRPC сервера, а по истечении ctx прерывает их
+func stopGRPC(ctx context.Context, srv *grpc.Server, zLog *zap.Logger) {
+	if srv == nil {
+		return
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		zLog.Info("gRPC server stopped")
+	case <-ctx.Done():
+		srv.Stop()
+		zLog.Error("gRPC server shutdown did not complete in time", zap.Error(ctx.Err()))
+	}
+}
@@ -1,67 +1,113 @@
 // Package main является точкой входа в приложение
 //
 // REST API-сервер, реализованный с использованием Gin, PostgreSQL, Gorm, Zap
-// Реализует систему обработки транзакций платёжной системы
+// Реализует систему обработки транзакций платёжной системы
 package main
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
 	"github.com/normalniydada/test_task_infotecs/internal/config"
-	"github.com/normalniydada/test_task_infotecs/internal/handlers"
-	"github.com/normalniydada/test_task_infotecs/internal/seeds"
-	"github.com/normalniydada/test_task_infotecs/internal/storage"
 	"github.com/normalniydada/test_task_infotecs/pkg/logger"
 	"go.uber.org/zap"
+	"os"
+	"os/user"
 )
 
-// main инициализирует и запускает HTTP-сервер
-//
-// Основные шаги выполнения:
-//   - Инициализация логгера (`zap.Logger`)
-//   - Чтение конфигурации из `config.yaml` с использованием Viper
-//   - Подключение к базе данных PostgreSQL через Gorm
-//   - Создание 10 тестовых кошельков (если они отсутствуют)
-//   - Регистрация API-обработчиков с использованием Gin
-//   - Запуск HTTP-сервера на указанном в конфигурации порту
-//
-// Сервер предоставляет следующие эндпоинты:
-//   - POST /api/send  — отправление средств с одного из кошельков на указанный кошелек
-//   - GET  /api/transactions?count=N  — получение списка последних N транзакций
-//   - GET  /api/wallet/{address}/balance  — получение баланса указанного кошелька
+// usage - справка по подкомандам
+const usage = `usage: main [command]
+
+commands:
+  serve                  run HTTP and gRPC servers (default)
+  migrate                apply database migrations
+  seed                   create initial wallets and the bootstrap admin key
+  reconcile [-json]      compare wallet balances with transaction history
+  wallet adjust -address ADDRESS -amount AMOUNT -reason-code CODE -note TEXT
+                         credit (positive amount) or debit (negative amount) a wallet against the equity wallet
+  export transactions|wallets [-format csv|json] [-since T] [-until T] [-out FILE]
+                         export data
+  config check [-config path]
+                         validate the config file
+`
+
+// main выбирает подкоманду по первому аргументу; без аргументов запускается сервер
 //
-// Если сервер не может быть запущен, программа завершает выполнение с критической ошибкой
+// Все подкоманды, работающие с базой данных, загружают конфигурацию через config.MustLoad
+// и подключаются через storage.InitDB так же, как сервер, и вызывают тот же сервисный слой,
+// поэтому данные изменяются с теми же проверками и записями журнала аудита, что и через API.
+// Изменения от имени подкоманд записываются в журнал аудита с субъектом "cli:<пользователь ОС>"
 func main() {
+	command, args := "serve", []string(nil)
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
+	}
+
+	switch command {
+	case "serve":
+		runServe()
+	case "migrate":
+		runMigrate()
+	case "seed":
+		runSeed()
+	case "reconcile":
+		os.Exit(runReconcile(args))
+	case "wallet":
+		if len(args) == 0 || args[0] != "adjust" {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		os.Exit(runWalletAdjust(args[1:]))
+	case "export":
+		os.Exit(runExport(args))
+	case "config":
+		os.Exit(runConfig(args))
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// load создаёт логгер и загружает конфигурацию; логгер пересоздаётся с настройками из конфигурации
+//
+// При ошибке конфигурации программа завершается с фатальной ошибкой
+func load() (*config.Config, *zap.Logger) {
 	// Инициализация логгера
 	zLog := logger.InitLogger()
-	defer zLog.Sync()
-
-	// Отключение логов Gin
-	gin.SetMode(gin.ReleaseMode)
 
 	// Загрузка конфигурации
 	cfg := config.MustLoad(zLog)
 
-	// Подключение к базе данных
-	db := storage.InitDB(&cfg.Database, zLog)
-	defer storage.CloseDB(db, zLog)
-
-	// Инициализация тестовых кошельков
-	seeds.InitWallets(db, zLog)
+	// Пересоздание логгера с уровнем и семплированием из конфигурации
+	zLog = newLogger(&cfg.Log, zLog)
+	zap.ReplaceGlobals(zLog)
+	return cfg, zLog
+}
 
-	// Создание HTTP-сервера
-	r := gin.Default()
-	api := r.Group("/api")
-	{
-		api.POST("/send", handlers.SendTransaction(db))
-		api.GET("/transactions", handlers.GetLastTransactions(db))
-		api.GET("/wallet/:address/balance", handlers.GetBalance(db))
+// cliContext возвращает контекст подкоманды с субъектом аудита "cli:<пользователь ОС>"
+func cliContext() context.Context {
+	actor := "cli"
+	if u, err := user.Current(); err == nil {
+		actor += ":" + u.Username
 	}
+	return audit.WithMeta(context.Background(), audit.Meta{Actor: actor})
+}
 
-	// Логирование запуска сервера
-	zLog.Info("Server is running...", zap.String("address", cfg.Server.Address))
+// newLogger создаёт логгер с уровнем и семплированием из конфигурации
+//
+// При ошибке создания программа завершается с фатальной ошибкой через исходный логгер
+func newLogger(cfg *config.LogConfig, zLog *zap.Logger) *zap.Logger {
+	opts := logger.Options{Level: cfg.Level}
+	if cfg.Sampling.Enabled {
+		opts.Sampling = &logger.Sampling{Initial: cfg.Sampling.Initial, Thereafter: cfg.Sampling.Thereafter}
+	}
 
-	// Запуск сервера
-	if err := r.Run(cfg.Server.Address); err != nil {
-		zLog.Fatal("Error start the server", zap.Error(err))
+	l, err := logger.New(opts)
+	if err != nil {
+		zLog.Fatal("Error init logger", zap.Error(err))
 	}
+	_ = zLog.Sync()
+	return l
 }
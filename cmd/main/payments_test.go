@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// TestWithdrawalRequiresWalletSignature проверяет, что вывод с кошелька с зарегистрированным ключом
+// требует подписи сообщения services.WithdrawalMessage и продвигает nonce кошелька
+func TestWithdrawalRequiresWalletSignature(t *testing.T) {
+	r, operatorKey, _ := newTestRouter(t)
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	w := serve(r, http.MethodPut, "/api/v1/wallet/"+testSender+"/key", operatorKey, `{"public_key": "`+hex.EncodeToString(pub)+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("register key: status = %d; body: %s", w.Code, w.Body)
+	}
+
+	sign := func(amount int64, externalID string, nonce uint64) string {
+		return hex.EncodeToString(ed25519.Sign(priv, services.WithdrawalMessage(testSender, amount, externalID, nonce)))
+	}
+	withdrawal := func(externalID string, nonce uint64, signature string) string {
+		return `{"wallet": "` + testSender + `", "amount": "1.00", "external_id": "` + externalID +
+			`", "nonce": ` + strconv.FormatUint(nonce, 10) + `, "signature": "` + signature + `"}`
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+	}{
+		{"unsigned", `{"wallet": "` + testSender + `", "amount": "1.00", "external_id": "psp-1"}`, http.StatusBadRequest, "signature_required"},
+		{"signed for another amount", withdrawal("psp-1", 1, sign(200, "psp-1", 1)), http.StatusBadRequest, "invalid_signature"},
+		{"transfer signature", withdrawal("psp-1", 1, hex.EncodeToString(ed25519.Sign(priv,
+			services.TransferMessage(testSender, testReceiver, 100, 1, services.TransferDetails{})))), http.StatusBadRequest, "invalid_signature"},
+		{"signed", withdrawal("psp-1", 1, sign(100, "psp-1", 1)), http.StatusCreated, ""},
+		{"replayed nonce", withdrawal("psp-2", 1, sign(100, "psp-2", 1)), http.StatusUnprocessableEntity, "invalid_nonce"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(r, http.MethodPost, "/api/v2/withdrawals", operatorKey, tt.body)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.code == "" {
+				return
+			}
+			var resp struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error.Code != tt.code {
+				t.Fatalf("code = %q, want %q; body: %s", resp.Error.Code, tt.code, w.Body)
+			}
+		})
+	}
+
+	w = serve(r, http.MethodGet, "/api/v1/wallet/"+testSender, operatorKey, "")
+	var wallet struct {
+		Nonce uint64 `json:"nonce"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &wallet); err != nil || wallet.Nonce != 1 {
+		t.Fatalf("wallet nonce = %d, want 1; body: %s", wallet.Nonce, w.Body)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/middleware"
+	"github.com/normalniydada/test_task_infotecs/internal/ratelimit"
+	"github.com/normalniydada/test_task_infotecs/internal/workers"
+	"gorm.io/gorm"
+)
+
+// newRateLimiter создаёт хранилище корзин токенов и запускает фоновую очистку неиспользуемых корзин
+//
+// Возвращает nil, если ограничение частоты запросов выключено
+func newRateLimiter(cfg *config.RateLimitConfig, db *gorm.DB, bg *workers.Group) ratelimit.Store {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.Store == "postgres" {
+		store = ratelimit.NewPostgresStore(db)
+	}
+
+	bg.Go("ratelimit-cleanup", func(ctx context.Context) {
+		store.Cleanup(ctx, cfg.CleanupInterval, cfg.IdleTTL)
+	})
+	return store
+}
+
+// ipRateLimit возвращает промежуточный обработчик ограничения запросов по IP-адресу
+// или пустой обработчик, если ограничение выключено
+func ipRateLimit(store ratelimit.Store, cfg *config.RateLimitConfig) gin.HandlerFunc {
+	if store == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.IPRateLimit(store, ratelimit.Limit{Rate: cfg.IP.Rate, Burst: cfg.IP.Burst})
+}
+
+// clientRateLimit возвращает промежуточный обработчик ограничения запросов по клиенту
+// или пустой обработчик, если ограничение выключено
+func clientRateLimit(store ratelimit.Store, cfg *config.RateLimitConfig) gin.HandlerFunc {
+	if store == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.ClientRateLimit(store, ratelimit.Limit{Rate: cfg.Client.Rate, Burst: cfg.Client.Burst})
+}
+
+// senderRateLimit возвращает промежуточный обработчик ограничения списаний по кошельку из поля field тела запроса
+// или пустой обработчик, если ограничение выключено
+func senderRateLimit(store ratelimit.Store, cfg *config.RateLimitConfig, maxBodyBytes int64, field string) gin.HandlerFunc {
+	if store == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.SenderRateLimit(store, ratelimit.Limit{Rate: cfg.Sender.Rate, Burst: cfg.Sender.Burst}, maxBodyBytes, field)
+}
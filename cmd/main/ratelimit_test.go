@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// keyRecorder - хранилище корзин, которое разрешает все запросы и запоминает ключи корзин
+type keyRecorder struct {
+	keys []string
+}
+
+// Take запоминает ключ корзины и разрешает запрос
+func (s *keyRecorder) Take(_ context.Context, key string, _ ratelimit.Limit) (ratelimit.Result, error) {
+	s.keys = append(s.keys, key)
+	return ratelimit.Result{Allowed: true}, nil
+}
+
+// Cleanup ничего не делает
+func (s *keyRecorder) Cleanup(context.Context, time.Duration, time.Duration) {}
+
+// TestIPRateLimitIgnoresForgedForwardedFor проверяет, что ключ корзины по IP-адресу берётся из заголовка
+// X-Forwarded-For, только если запрос пришёл от прокси из server.trusted_proxies
+func TestIPRateLimitIgnoresForgedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   []string
+		want           string
+	}{
+		{"no trusted proxies", nil, "203.0.113.7:40000", []string{"198.51.100.1", "198.51.100.2"}, "ip:203.0.113.7"},
+		{"untrusted proxy", []string{"10.0.0.0/8"}, "203.0.113.7:40000", []string{"198.51.100.1", "198.51.100.2"}, "ip:203.0.113.7"},
+		{"trusted proxy", []string{"10.0.0.0/8"}, "10.1.2.3:40000", []string{"198.51.100.1"}, "ip:198.51.100.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newEngine(&config.ServerConfig{TrustedProxies: tt.trustedProxies})
+			if err != nil {
+				t.Fatalf("new engine: %v", err)
+			}
+			store := &keyRecorder{}
+			r.GET("/ping", ipRateLimit(store, &config.RateLimitConfig{IP: config.LimitConfig{Rate: 1, Burst: 1}}),
+				func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+			for _, forwarded := range tt.forwardedFor {
+				req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+				req.RemoteAddr = tt.remoteAddr
+				req.Header.Set("X-Forwarded-For", forwarded)
+				r.ServeHTTP(httptest.NewRecorder(), req)
+			}
+
+			if len(store.keys) != len(tt.forwardedFor) {
+				t.Fatalf("keys = %v, want %d requests", store.keys, len(tt.forwardedFor))
+			}
+			if i := slices.IndexFunc(store.keys, func(k string) bool { return k != tt.want }); i >= 0 {
+				t.Fatalf("bucket key = %q, want %q", store.keys[i], tt.want)
+			}
+		})
+	}
+}
+
+// TestSenderRateLimitChargesOnlyOwnWallets проверяет, что корзина отправителя списывается только
+// для кошельков, с которых субъекту запроса разрешено списывать средства
+func TestSenderRateLimitChargesOnlyOwnWallets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	p := &auth.Principal{Subject: "api_key:1", Role: auth.RoleOperator, Wallets: []string{testSender}}
+
+	// Переводы указывают кошелёк в поле "from", выводы средств - в поле "wallet"
+	for _, field := range []string{"from", "wallet"} {
+		t.Run(field, func(t *testing.T) {
+			store := &keyRecorder{}
+			r := gin.New()
+			r.POST("/debit",
+				func(c *gin.Context) { c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p)) },
+				senderRateLimit(store, &config.RateLimitConfig{Sender: config.LimitConfig{Rate: 1, Burst: 1}}, 1<<10, field),
+				func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+			for _, from := range []string{testReceiver, testSender} {
+				req := httptest.NewRequest(http.MethodPost, "/debit", strings.NewReader(`{"`+field+`": "`+from+`"}`))
+				r.ServeHTTP(httptest.NewRecorder(), req)
+			}
+
+			if want := []string{"sender:" + testSender}; !slices.Equal(store.keys, want) {
+				t.Fatalf("bucket keys = %v, want %v", store.keys, want)
+			}
+		})
+	}
+}
+
+// TestNewEngineRejectsInvalidTrustedProxy проверяет, что некорректный адрес прокси не даёт запустить сервер
+func TestNewEngineRejectsInvalidTrustedProxy(t *testing.T) {
+	if _, err := newEngine(&config.ServerConfig{TrustedProxies: []string{"not-a-proxy"}}); err == nil {
+		t.Fatal("want error for invalid trusted proxy")
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/apiversion"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/handlers"
+	"github.com/normalniydada/test_task_infotecs/internal/middleware"
+	"github.com/normalniydada/test_task_infotecs/internal/ratelimit"
+	"github.com/normalniydada/test_task_infotecs/internal/webhooks"
+	"gorm.io/gorm"
+)
+
+// apiDeps - зависимости обработчиков API, общие для всех версий
+type apiDeps struct {
+	db          *gorm.DB
+	cfg         *config.Config
+	jwtVerifier *auth.JWTVerifier
+	limiter     ratelimit.Store
+	dispatcher  *webhooks.Dispatcher
+	apiRouter   routers.Router
+}
+
+// registerAPI регистрирует версии API на одних и тех же обработчиках и сервисах
+//
+// Маршруты:
+//   - /api/v1 - текущий формат ответов, помечен заголовками Deprecation, Sunset и Link
+//   - /api - псевдоним /api/v1 для существующих клиентов
+//   - /api/v2 - суммы строками, ошибки {"error": {"code": ..., "message": ...}}
+func registerAPI(r *gin.Engine, deps *apiDeps) {
+	deprecatedAt, sunset := deps.cfg.API.V1Dates()
+	v1 := []gin.HandlerFunc{apiversion.Use(apiversion.V1), apiversion.Deprecate(deprecatedAt, sunset)}
+
+	registerRoutes(r.Group("/api/v1", v1...), deps)
+	registerRoutes(r.Group("/api", v1...), deps)
+	registerRoutes(r.Group("/api/v2", apiversion.Use(apiversion.V2)), deps)
+}
+
+// registerRoutes регистрирует маршруты одной версии API
+//
+// Версия уже сохранена в контексте группы (apiversion.Use) и определяет формат сумм и ошибок в обработчиках
+func registerRoutes(api *gin.RouterGroup, deps *apiDeps) {
+	db, cfg := deps.db, deps.cfg
+	api.Use(
+		ipRateLimit(deps.limiter, &cfg.RateLimit),
+		middleware.Authenticate(db, &cfg.Auth, deps.jwtVerifier),
+		clientRateLimit(deps.limiter, &cfg.RateLimit),
+		middleware.ValidateOpenAPI(deps.apiRouter, &cfg.OpenAPI),
+	)
+
+	viewer := api.Group("", middleware.RequireRole(auth.RoleViewer))
+	viewer.GET("/transactions", handlers.GetLastTransactions(db))
+	viewer.GET("/transactions/:id", handlers.GetTransaction(db))
+	viewer.GET("/transactions/stream", handlers.StreamTransactions(db, &cfg.Stream))
+	viewer.GET("/wallet/:address", handlers.GetWallet(db))
+	viewer.GET("/wallet/:address/balance", handlers.GetBalance(db))
+	viewer.GET("/ws/balances", handlers.SubscribeBalances(db, &cfg.Stream))
+	viewer.GET("/payments/:id", handlers.GetPayment(db))
+
+	operator := api.Group("", middleware.RequireRole(auth.RoleOperator))
+	operator.POST("/send", senderRateLimit(deps.limiter, &cfg.RateLimit, cfg.Server.MaxBodyBytes, "from"), handlers.SendTransaction(db))
+	operator.PUT("/wallet/:address/key", handlers.RegisterWalletKey(db))
+	operator.POST("/deposits", handlers.CreateDeposit(db))
+	operator.POST("/withdrawals", senderRateLimit(deps.limiter, &cfg.RateLimit, cfg.Server.MaxBodyBytes, "wallet"), handlers.CreateWithdrawal(db))
+	operator.POST("/webhooks", handlers.CreateWebhook(db))
+	operator.GET("/webhooks", handlers.ListWebhooks(db))
+	operator.DELETE("/webhooks/:id", handlers.DeleteWebhook(db))
+	operator.GET("/webhooks/:id/events", handlers.ListWebhookEvents(db))
+	operator.POST("/webhooks/events/:id/redeliver", handlers.RedeliverWebhookEvent(db, deps.dispatcher))
+
+	admin := api.Group("/admin", middleware.RequireRole(auth.RoleAdmin))
+	admin.POST("/keys", handlers.CreateAPIKey(db))
+	admin.GET("/keys", handlers.ListAPIKeys(db))
+	admin.PUT("/keys/:id/wallets", handlers.SetAPIKeyWallets(db))
+	admin.DELETE("/keys/:id", handlers.RevokeAPIKey(db))
+	admin.POST("/wallets", handlers.CreateWallet(db))
+	admin.POST("/wallets/:address/freeze", handlers.FreezeWallet(db))
+	admin.DELETE("/wallets/:address/freeze", handlers.UnfreezeWallet(db))
+	admin.POST("/wallets/:address/adjustments", handlers.AdjustWallet(db))
+	admin.GET("/audit", handlers.GetAuditLogs(db))
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/middleware"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"github.com/normalniydada/test_task_infotecs/internal/openapi"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"github.com/normalniydada/test_task_infotecs/internal/storage"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Кошельки тестовой базы данных: ключ оператора привязан только к testSender
+const (
+	testSender   = "wallet-sender"
+	testReceiver = "wallet-receiver"
+)
+
+// bearerPrefix - префикс JWT в заголовке Authorization
+const bearerPrefix = "Bearer "
+
+// testOpenAPIConfig включает обе проверки по OpenAPI-описанию
+var testOpenAPIConfig = config.OpenAPIConfig{ValidateRequests: true, ValidateResponses: true}
+
+// testJWTConfig - проверка JWT с общим секретом HMAC; утверждение roles сопоставляется только значению "support"
+var testJWTConfig = config.JWTConfig{
+	Enabled:     true,
+	StaticKey:   "test-jwt-secret-0123456789abcdef",
+	RolesClaim:  "roles",
+	RoleMapping: map[string]string{"support": "viewer"},
+}
+
+// bearer возвращает значение заголовка Authorization с токеном, подписанным секретом testJWTConfig
+func bearer(t *testing.T, roles ...string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "staff@example.com",
+		"roles": roles,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testJWTConfig.StaticKey))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return bearerPrefix + token
+}
+
+// newTestRouter собирает маршруты API так же, как команда serve, с проверкой запросов и ответов по описанию
+//
+// Вместо PostgreSQL используется SQLite в памяти со схемой storage.Models; ограничение частоты запросов выключено.
+// Возвращает маршрутизатор и ключи доступа оператора и администратора
+func newTestRouter(t *testing.T) (r *gin.Engine, operatorKey, adminKey string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sqlite pool: %v", err)
+	}
+	// Каждое соединение с базой в памяти видит свою базу данных
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := db.AutoMigrate(storage.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	for _, w := range []models.Wallet{{Address: testSender, Balance: 10000}, {Address: testReceiver, Balance: 10000}} {
+		if err := db.Create(&w).Error; err != nil {
+			t.Fatalf("create wallet: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	if operatorKey, _, err = services.CreateAPIKey(ctx, db, "operator", false, []string{testSender}); err != nil {
+		t.Fatalf("create operator key: %v", err)
+	}
+	if adminKey, _, err = services.CreateAPIKey(ctx, db, "admin", true, nil); err != nil {
+		t.Fatalf("create admin key: %v", err)
+	}
+
+	_, apiRouter, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load openapi: %v", err)
+	}
+	cfg := &config.Config{
+		Server:  config.ServerConfig{MaxBodyBytes: 1 << 20},
+		Auth:    config.AuthConfig{Enabled: true, JWT: testJWTConfig},
+		OpenAPI: testOpenAPIConfig,
+		API:     config.APIConfig{V1DeprecatedAt: "2026-10-19"},
+	}
+
+	jwtVerifier, err := auth.NewJWTVerifier(&cfg.Auth.JWT)
+	if err != nil {
+		t.Fatalf("jwt verifier: %v", err)
+	}
+
+	r = gin.New()
+	registerAPI(r, &apiDeps{db: db, cfg: cfg, jwtVerifier: jwtVerifier, apiRouter: apiRouter})
+	return r, operatorKey, adminKey
+}
+
+// serve выполняет запрос к маршрутизатору; key - ключ доступа или заголовок Authorization с JWT (см. bearer),
+// пустой key - запрос без учётных данных
+func serve(r http.Handler, method, target, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case strings.HasPrefix(key, bearerPrefix):
+		req.Header.Set("Authorization", key)
+	case key != "":
+		req.Header.Set(middleware.APIKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestAPIResponsesMatchOpenAPI проверяет, что ответы описанных операций проходят проверку по описанию:
+// при включённой validate_responses несоответствие превратило бы ожидаемый статус в 500
+//
+// API v1 сохраняет прежние коды: ошибки перевода - 400, отсутствующий кошелёк в балансе - 500,
+// поэтому 404 возвращается только для путей, которых нет в API
+func TestAPIResponsesMatchOpenAPI(t *testing.T) {
+	r, operatorKey, adminKey := newTestRouter(t)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		key    string
+		body   string
+		status int
+	}{
+		{"send", http.MethodPost, "/api/v1/send", operatorKey,
+			`{"from": "` + testSender + `", "to": "` + testReceiver + `", "amount": 1.5, "reference": "order-1"}`, http.StatusOK},
+		{"send via alias", http.MethodPost, "/api/send", operatorKey,
+			`{"from": "` + testSender + `", "to": "` + testReceiver + `", "amount": 2}`, http.StatusOK},
+		{"send without amount", http.MethodPost, "/api/v1/send", operatorKey,
+			`{"from": "` + testSender + `", "to": "` + testReceiver + `"}`, http.StatusBadRequest},
+		{"send to self", http.MethodPost, "/api/v1/send", operatorKey,
+			`{"from": "` + testSender + `", "to": "` + testSender + `", "amount": 1}`, http.StatusBadRequest},
+		{"send to unknown wallet", http.MethodPost, "/api/v1/send", operatorKey,
+			`{"from": "` + testSender + `", "to": "unknown", "amount": 1}`, http.StatusBadRequest},
+		{"send without credentials", http.MethodPost, "/api/v1/send", "",
+			`{"from": "` + testSender + `", "to": "` + testReceiver + `", "amount": 1}`, http.StatusUnauthorized},
+		{"send from foreign wallet", http.MethodPost, "/api/v1/send", operatorKey,
+			`{"from": "` + testReceiver + `", "to": "` + testSender + `", "amount": 1}`, http.StatusForbidden},
+		{"send to unknown route", http.MethodPost, "/api/v1/send/now", operatorKey,
+			`{"from": "` + testSender + `", "to": "` + testReceiver + `", "amount": 1}`, http.StatusNotFound},
+
+		{"transactions", http.MethodGet, "/api/v1/transactions?count=5", operatorKey, "", http.StatusOK},
+		{"transactions by reference", http.MethodGet, "/api/v1/transactions?count=5&reference=order-1", adminKey, "", http.StatusOK},
+		{"transactions with failed attempts", http.MethodGet, "/api/v1/transactions?count=5&status=all", adminKey, "", http.StatusOK},
+		{"transactions without count", http.MethodGet, "/api/v1/transactions", operatorKey, "", http.StatusBadRequest},
+		{"transactions with zero count", http.MethodGet, "/api/v1/transactions?count=0", operatorKey, "", http.StatusBadRequest},
+		{"transactions with unknown status", http.MethodGet, "/api/v1/transactions?count=5&status=lost", operatorKey, "", http.StatusBadRequest},
+		{"transactions without credentials", http.MethodGet, "/api/v1/transactions?count=5", "", "", http.StatusUnauthorized},
+		{"transactions with staff token", http.MethodGet, "/api/v1/transactions?count=5", bearer(t, "support"), "", http.StatusOK},
+		{"transactions without role", http.MethodGet, "/api/v1/transactions?count=5", bearer(t, "marketing"), "", http.StatusForbidden},
+
+		{"balance", http.MethodGet, "/api/v1/wallet/" + testSender + "/balance", operatorKey, "", http.StatusOK},
+		{"balance without credentials", http.MethodGet, "/api/v1/wallet/" + testSender + "/balance", "", "", http.StatusUnauthorized},
+		{"balance with invalid key", http.MethodGet, "/api/v1/wallet/" + testSender + "/balance", "wk_invalid", "", http.StatusUnauthorized},
+		{"balance of foreign wallet", http.MethodGet, "/api/v1/wallet/" + testReceiver + "/balance", operatorKey, "", http.StatusForbidden},
+		{"balance of unknown wallet", http.MethodGet, "/api/v1/wallet/unknown/balance", adminKey, "", http.StatusInternalServerError},
+		{"balance of unknown route", http.MethodGet, "/api/v1/wallet/" + testSender + "/balances", operatorKey, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(r, tt.method, tt.target, tt.key, tt.body)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body: %s", w.Code, tt.status, w.Body)
+			}
+			if strings.Contains(w.Body.String(), "response does not match api specification") {
+				t.Fatalf("response rejected by OpenAPI validation: %s", w.Body)
+			}
+		})
+	}
+}
+
+// TestOpenAPIRejectsNonConformingResponse проверяет, что ответ, нарушающий описание, заменяется на 500
+func TestOpenAPIRejectsNonConformingResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, apiRouter, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load openapi: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		body   any
+		want   int
+	}{
+		{"conforming", http.StatusOK, gin.H{"balance": 100.5}, http.StatusOK},
+		{"balance as string", http.StatusOK, gin.H{"balance": "100.50"}, http.StatusInternalServerError},
+		{"missing balance", http.StatusOK, gin.H{"amount": 100.5}, http.StatusInternalServerError},
+		{"error without message", http.StatusForbidden, gin.H{"code": "forbidden"}, http.StatusInternalServerError},
+		{"undocumented status", http.StatusConflict, gin.H{"error": "conflict"}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(middleware.ValidateOpenAPI(apiRouter, &testOpenAPIConfig))
+			r.GET("/api/v1/wallet/:address/balance", func(c *gin.Context) {
+				c.JSON(tt.status, tt.body)
+			})
+
+			w := serve(r, http.MethodGet, "/api/v1/wallet/"+testSender+"/balance", "", "")
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d; body: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want == http.StatusInternalServerError {
+				var resp struct {
+					Error string `json:"error"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error == "" {
+					t.Fatalf("want v1 error body, got %s", w.Body)
+				}
+			}
+		})
+	}
+}
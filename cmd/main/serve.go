@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/events"
+	"github.com/normalniydada/test_task_infotecs/internal/handlers"
+	"github.com/normalniydada/test_task_infotecs/internal/health"
+	"github.com/normalniydada/test_task_infotecs/internal/metrics"
+	"github.com/normalniydada/test_task_infotecs/internal/middleware"
+	"github.com/normalniydada/test_task_infotecs/internal/openapi"
+	"github.com/normalniydada/test_task_infotecs/internal/seeds"
+	"github.com/normalniydada/test_task_infotecs/internal/storage"
+	"github.com/normalniydada/test_task_infotecs/internal/tracing"
+	"github.com/normalniydada/test_task_infotecs/internal/webhooks"
+	"github.com/normalniydada/test_task_infotecs/internal/workers"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.uber.org/zap"
+	"net/http"
+	"os/signal"
+	"syscall"
+)
+
+// runServe инициализирует и запускает HTTP-сервер (подкоманда `serve`)
+//
+// Основные шаги выполнения:
+//   - Инициализация логгера и чтение конфигурации (см. load)
+//   - Настройка трассировки OpenTelemetry
+//   - Подключение к базе данных PostgreSQL через Gorm
+//   - Начальное заполнение кошельками, если оно включено (seed.enabled) и кошельков нет
+//   - Регистрация API-обработчиков с использованием Gin
+//   - Запуск HTTP-сервера на указанном в конфигурации порту
+//   - Корректная остановка по сигналу SIGINT/SIGTERM
+//
+// Сервер предоставляет следующие эндпоинты:
+//   - GET  /healthz  — проверка, что процесс запущен
+//   - GET  /readyz  — проверка готовности (БД, миграции, фоновые задачи)
+//   - GET  /metrics  — метрики в формате Prometheus
+//   - GET  /api/openapi.json, /api/docs  — OpenAPI-описание API и Swagger UI (без аутентификации)
+//   - POST /api/send  — отправление средств с одного из кошельков на указанный кошелек
+//   - GET  /api/transactions?count=N  — получение списка последних N транзакций
+//   - GET  /api/transactions/{id}  — получение транзакции по идентификатору
+//   - GET  /api/transactions/stream  — поток новых транзакций (Server-Sent Events)
+//   - GET  /api/wallet/{address}/balance  — получение баланса указанного кошелька
+//   - GET  /api/ws/balances  — подписка на изменения балансов кошельков (WebSocket)
+//   - GET  /api/wallet/{address}  — информация о кошельке (баланс, публичный ключ, nonce)
+//   - PUT  /api/wallet/{address}/key  — регистрация публичного ключа Ed25519 кошелька
+//   - POST /api/deposits, POST /api/withdrawals, GET /api/payments/{id}  — пополнения и выводы средств через казначейство
+//   - POST /api/payments/callback  — уведомление платёжного провайдера (подпись X-Payment-Signature, без ключа доступа, с ограничением по IP-адресу)
+//   - POST/GET /api/webhooks, DELETE /api/webhooks/{id}  — подписки на вебхуки кошельков
+//   - GET  /api/webhooks/{id}/events, POST /api/webhooks/events/{id}/redeliver  — попытки доставки и повторная доставка
+//   - POST /api/admin/wallets  — создание кошелька (адрес выводится из публичного ключа, если он передан)
+//   - POST/DELETE /api/admin/wallets/{address}/freeze  — заморозка кошелька и её снятие
+//   - POST /api/admin/wallets/{address}/adjustments  — ручная корректировка баланса через кошелёк капитала
+//   - POST/GET /api/admin/keys, PUT /api/admin/keys/{id}/wallets, DELETE /api/admin/keys/{id}  — управление ключами доступа
+//   - GET  /api/admin/audit  — чтение журнала аудита с фильтрами
+//
+// Эндпоинты /api доступны также по /api/v1 (формат текущей версии, ответы с заголовком Deprecation)
+// и /api/v2 (суммы строками, ошибки `{"error": {"code": ..., "message": ...}}`).
+//
+// Запросы к /api требуют ключ доступа в заголовке `X-API-Key` или JWT в заголовке
+// `Authorization: Bearer`. Чтение доступно роли viewer, переводы - operator, администрирование - admin.
+//
+// Запросы к описанным в OpenAPI операциям /api и /api/v1 проверяются по описанию (internal/openapi/openapi.yaml);
+// проверка ответов включается параметром `openapi.validate_responses`. Для /api/v2 описания нет, его запросы
+// и ответы не проверяются.
+//
+// Каждому запросу назначается `X-Request-ID`; журнал доступа пишется через zap.
+//
+// На отдельном адресе `grpc.address` работает gRPC API WalletService (api/proto/wallet/v1/wallet.proto)
+// с теми же сервисным слоем, аутентификацией и ролями.
+//
+// Если сервер не может быть запущен, программа завершает выполнение с критической ошибкой
+func runServe() {
+	cfg, zLog := load()
+
+	// Отключение отладочных сообщений Gin
+	gin.SetMode(gin.ReleaseMode)
+
+	// Инициализация трассировки
+	shutdownTracing, err := tracing.Init(&cfg.Tracing)
+	if err != nil {
+		zLog.Fatal("Error init tracing", zap.Error(err))
+	}
+
+	// Подключение к базе данных
+	db := storage.InitDB(&cfg.Database, zLog)
+
+	// Инициализация тестовых кошельков
+	if cfg.Seed.Enabled {
+		seeds.InitWallets(db, &cfg.Seed, zLog)
+	}
+	seeds.InitAdminKey(db, cfg.Auth.BootstrapAdminKey, zLog)
+
+	// Проверка JWT сотрудников back-office
+	var jwtVerifier *auth.JWTVerifier
+	if cfg.Auth.JWT.Enabled {
+		if jwtVerifier, err = auth.NewJWTVerifier(&cfg.Auth.JWT); err != nil {
+			zLog.Fatal("Error init JWT verifier", zap.Error(err))
+		}
+	}
+
+	// Фоновые задачи приложения
+	bg := workers.NewGroup(zLog)
+
+	// Ограничение частоты запросов
+	limiter := newRateLimiter(&cfg.RateLimit, db, bg)
+
+	// Доставка вебхуков
+	dispatcher := webhooks.NewDispatcher(db, &cfg.Webhooks)
+	if cfg.Webhooks.Enabled {
+		bg.Go("webhook-dispatcher", dispatcher.Run)
+	}
+
+	// OpenAPI-описание HTTP API
+	apiDoc, apiRouter, err := openapi.Load()
+	if err != nil {
+		zLog.Fatal("Error load OpenAPI specification", zap.Error(err))
+	}
+
+	// Проверка готовности зависимостей
+	checker := health.NewChecker(db, bg, storage.Models())
+
+	// Создание HTTP-сервера
+	r, err := newEngine(&cfg.Server)
+	if err != nil {
+		zLog.Fatal("Error configure trusted proxies", zap.Error(err))
+	}
+	r.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+	r.Use(middleware.RequestID(zLog))
+	r.Use(middleware.AccessLog())
+	r.Use(middleware.Recovery())
+	r.Use(metrics.Middleware())
+	r.GET("/metrics", metrics.Handler())
+	r.GET("/healthz", handlers.Healthz())
+	r.GET("/readyz", handlers.Readyz(checker))
+	r.GET("/api/openapi.json", openapi.Handler(apiDoc))
+	r.GET("/api/docs", openapi.SwaggerUI())
+	r.POST("/api/payments/callback", ipRateLimit(limiter, &cfg.RateLimit), handlers.PaymentCallback(db, &cfg.Payments))
+
+	registerAPI(r, &apiDeps{
+		db:          db,
+		cfg:         cfg,
+		jwtVerifier: jwtVerifier,
+		limiter:     limiter,
+		dispatcher:  dispatcher,
+		apiRouter:   apiRouter,
+	})
+
+	srv := &http.Server{
+		Addr:              cfg.Server.Address,
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	// Потоковые соединения не завершаются сами, поэтому при остановке их подписки закрываются
+	srv.RegisterOnShutdown(events.CloseAll)
+
+	// Ожидание сигнала остановки
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Логирование запуска сервера
+	zLog.Info("Server is running...", zap.String("address", cfg.Server.Address))
+
+	// Запуск HTTP и gRPC серверов
+	serverErr := make(chan error, 2)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+	grpcSrv := startGRPC(cfg, db, jwtVerifier, limiter, zLog, serverErr)
+
+	select {
+	case err := <-serverErr:
+		zLog.Fatal("Error start the server", zap.Error(err))
+	case <-ctx.Done():
+		zLog.Info("Shutdown signal received, draining in-flight requests",
+			zap.Duration("timeout", cfg.Server.ShutdownTimeout))
+	}
+
+	shutdown(srv, grpcSrv, checker, bg, shutdownTracing, db, cfg, zLog)
+}
+
+// newEngine создаёт маршрутизатор Gin, который доверяет X-Forwarded-For только прокси из `server.trusted_proxies`
+//
+// По умолчанию Gin доверяет любому прокси, и c.ClientIP() берётся из заголовка, который задаёт сам клиент;
+// от адреса клиента зависят ограничения частоты запросов по IP-адресу и адрес в журнале аудита
+func newEngine(cfg *config.ServerConfig) (*gin.Engine, error) {
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/health"
+	"github.com/normalniydada/test_task_infotecs/internal/storage"
+	"github.com/normalniydada/test_task_infotecs/internal/workers"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+// shutdown корректно останавливает приложение
+//
+// Порядок остановки:
+//  1. Перевод проверки готовности в состояние "fail" и ожидание `server.drain_delay`
+//  2. Прекращение приёма новых соединений, закрытие потоков событий и ожидание завершения обрабатываемых запросов
+//  3. Остановка gRPC сервера с ожиданием текущих вызовов
+//  4. Остановка фоновых задач
+//  5. Отправка накопленных спанов трассировки
+//  6. Закрытие соединения с базой данных
+//  7. Сброс буферов логгера
+//
+// Шаги 2-5 ограничены общим таймаутом `server.shutdown_timeout`
+func shutdown(srv *http.Server, grpcSrv *grpc.Server, checker *health.Checker, bg *workers.Group, shutdownTracing func(context.Context) error, db *gorm.DB, cfg *config.Config, zLog *zap.Logger) {
+	checker.SetShuttingDown()
+	time.Sleep(cfg.Server.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		zLog.Error("HTTP server shutdown did not complete in time", zap.Error(err))
+	} else {
+		zLog.Info("HTTP server stopped")
+	}
+
+	stopGRPC(ctx, grpcSrv, zLog)
+
+	if err := bg.Stop(ctx); err != nil {
+		zLog.Error("Background workers did not stop in time", zap.Error(err))
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		zLog.Error("Error flushing traces", zap.Error(err))
+	}
+
+	storage.CloseDB(db, zLog)
+
+	zLog.Info("Server stopped")
+	_ = zLog.Sync()
+}
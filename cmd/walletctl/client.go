@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// apiKeyHeader - заголовок с ключом доступа к API
+const apiKeyHeader = "X-API-Key"
+
+// errUnavailable - ошибка: сервер недоступен или ответил не в формате API
+var errUnavailable = errors.New("server unavailable")
+
+// apiError - ошибка, возвращённая API v2 в формате {"error": {"code": ..., "message": ...}}
+type apiError struct {
+	Status  int    // HTTP-статус ответа
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error возвращает сообщение и код ошибки API
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (%s, HTTP %d)", e.Message, e.Code, e.Status)
+}
+
+// client выполняет запросы к /api/v2
+type client struct {
+	server string
+	apiKey string
+	http   *http.Client
+}
+
+// do выполняет запрос и возвращает тело успешного ответа
+//
+// Параметры:
+//   - method, path: метод и путь относительно /api/v2, например "/wallet/abc/balance"
+//   - body: тело запроса, кодируемое в JSON; nil - без тела
+//
+// Возвращает:
+//   - []byte: тело ответа 2xx
+//   - error: *apiError для ответа с ошибкой API; errUnavailable, если сервер недоступен
+func (c *client) do(ctx context.Context, method, path string, body any) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.server, "/")+"/api/v2"+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errUnavailable, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return data, nil
+	}
+
+	var errBody struct {
+		Error *apiError `json:"error"`
+	}
+	if err = json.Unmarshal(data, &errBody); err != nil || errBody.Error == nil || errBody.Error.Code == "" {
+		return nil, fmt.Errorf("%w: unexpected response %s", errUnavailable, resp.Status)
+	}
+	errBody.Error.Status = resp.StatusCode
+	return nil, errBody.Error
+}
+
+// call выполняет запрос и разбирает тело ответа в out; возвращает исходное тело для вывода в JSON
+func (c *client) call(ctx context.Context, method, path string, body, out any) ([]byte, error) {
+	data, err := c.do(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, out); err != nil {
+		return nil, fmt.Errorf("%w: decode response: %w", errUnavailable, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// send выполняет перевод: send -from A -to B -amount 33.30 [-nonce N -signature HEX | -nonce N -key FILE] [-memo TEXT -reference REF -meta KEY=VALUE]
+//
+// POST /api/v2/send
+//
+// Флаг -meta можно повторять, каждое вхождение добавляет пару метаданных. С флагом -key перевод
+// подписывается ключом из файла (см. signTransfer), подпись покрывает и комментарий, идентификатор и метаданные
+func (a *app) send(ctx context.Context, args []string) error {
+	var req dto.TransactionRequestV2
+	var amount, keyFile string
+	fs := flag.NewFlagSet("send", flag.ContinueOnError)
+	fs.StringVar(&req.From, "from", "", "sender wallet address")
+	fs.StringVar(&req.To, "to", "", "receiver wallet address")
+	fs.StringVar(&amount, "amount", "", "amount, e.g. 33.30")
+	fs.Uint64Var(&req.Nonce, "nonce", 0, "nonce of a signed transfer")
+	fs.StringVar(&req.Signature, "signature", "", "Ed25519 signature in hex")
+	fs.StringVar(&keyFile, "key", "", "file with the sender's Ed25519 private key in hex; signs the transfer")
+	fs.StringVar(&req.Memo, "memo", "", "free-text memo")
+	fs.StringVar(&req.Reference, "reference", "", "external reference, unique per sender wallet")
+	fs.Var((*metadataFlag)(&req.Metadata), "meta", "metadata entry KEY=VALUE (repeatable)")
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if req.From == "" || req.To == "" || amount == "" || fs.NArg() != 0 {
+		return fmt.Errorf("%w: send requires -from, -to and -amount", errUsage)
+	}
+
+	var err error
+	if req.Amount, err = dto.ParseAmount(amount); err != nil {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+	if keyFile != "" {
+		if req.Nonce == 0 || req.Signature != "" {
+			return fmt.Errorf("%w: -key requires -nonce and cannot be combined with -signature", errUsage)
+		}
+		if req.Signature, err = signTransfer(keyFile, &req); err != nil {
+			return err
+		}
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	var transaction dto.TransactionResponse
+	raw, err := c.call(ctx, http.MethodPost, "/send", req, &transaction)
+	if err != nil {
+		return err
+	}
+	return a.print(raw, transactionsTable(transaction))
+}
+
+// signTransfer подписывает перевод req ключом Ed25519 из файла path
+//
+// Файл содержит ключ в hex: 32-байтовое зерно или 64-байтовый закрытый ключ. Подписывается сообщение
+// services.TransferMessage, включающее сведения о переводе
+func signTransfer(path string, req *dto.TransactionRequestV2) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read key: %w", err)
+	}
+	raw, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	var key ed25519.PrivateKey
+	switch {
+	case err == nil && len(raw) == ed25519.SeedSize:
+		key = ed25519.NewKeyFromSeed(raw)
+	case err == nil && len(raw) == ed25519.PrivateKeySize:
+		key = ed25519.PrivateKey(raw)
+	default:
+		return "", fmt.Errorf("%w: %s does not contain an Ed25519 private key in hex", errUsage, path)
+	}
+
+	details := services.TransferDetails{Memo: req.Memo, Reference: req.Reference, Metadata: req.Metadata}
+	message := services.TransferMessage(req.From, req.To, int64(req.Amount), req.Nonce, details)
+	return hex.EncodeToString(ed25519.Sign(key, message)), nil
+}
+
+// metadataFlag - значение флага -meta: пары KEY=VALUE, накапливаемые в карте метаданных
+type metadataFlag map[string]string
+
+// String возвращает пары флага через запятую
+func (m *metadataFlag) String() string {
+	if m == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*m))
+	for k, v := range *m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set добавляет пару KEY=VALUE
+func (m *metadataFlag) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("metadata entry must be KEY=VALUE, got %q", value)
+	}
+	if *m == nil {
+		*m = make(metadataFlag)
+	}
+	(*m)[k] = v
+	return nil
+}
+
+// balance выводит баланс кошелька: balance ADDRESS
+//
+// GET /api/v2/wallet/{address}/balance
+func (a *app) balance(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	address := args[0]
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	var balance dto.BalanceResponseV2
+	raw, err := c.call(ctx, http.MethodGet, "/wallet/"+url.PathEscape(address)+"/balance", nil, &balance)
+	if err != nil {
+		return err
+	}
+	return a.print(raw, func(w io.Writer) {
+		fmt.Fprintln(w, "ADDRESS\tBALANCE")
+		fmt.Fprintf(w, "%s\t%s\n", address, balance.Balance)
+	})
+}
+
+// txList выводит последние транзакции: tx list [-count N] [-reference REF] [-status STATUS]
+//
+// GET /api/v2/transactions?count=N[&reference=REF][&status=STATUS]
+func (a *app) txList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tx list", flag.ContinueOnError)
+	count := fs.Int("count", 10, "number of transactions")
+	reference := fs.String("reference", "", "only transactions with this external reference")
+	status := fs.String("status", "", "only transactions in this status (pending, completed, failed, reversed) or all; default excludes failed")
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *count <= 0 || fs.NArg() != 0 {
+		return fmt.Errorf("%w: -count must be positive", errUsage)
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	var transactions []dto.TransactionResponse
+	query := url.Values{"count": {strconv.Itoa(*count)}}
+	if *reference != "" {
+		query.Set("reference", *reference)
+	}
+	if *status != "" {
+		query.Set("status", *status)
+	}
+	raw, err := c.call(ctx, http.MethodGet, "/transactions?"+query.Encode(), nil, &transactions)
+	if err != nil {
+		return err
+	}
+	return a.print(raw, transactionsTable(transactions...))
+}
+
+// txGet выводит транзакцию: tx get ID
+//
+// GET /api/v2/transactions/{id}
+func (a *app) txGet(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if _, err := strconv.ParseUint(args[0], 10, 64); err != nil {
+		return fmt.Errorf("%w: invalid transaction id %q", errUsage, args[0])
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	var transaction dto.TransactionResponse
+	raw, err := c.call(ctx, http.MethodGet, "/transactions/"+args[0], nil, &transaction)
+	if err != nil {
+		return err
+	}
+	return a.print(raw, transactionsTable(transaction))
+}
+
+// walletCreate создаёт кошелёк: wallet create [-public-key HEX]
+//
+// POST /api/v2/admin/wallets
+func (a *app) walletCreate(ctx context.Context, args []string) error {
+	var req dto.CreateWalletRequest
+	fs := flag.NewFlagSet("wallet create", flag.ContinueOnError)
+	fs.StringVar(&req.PublicKey, "public-key", "", "Ed25519 public key in hex; the address is derived from it")
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	var wallet dto.WalletResponseV2
+	raw, err := c.call(ctx, http.MethodPost, "/admin/wallets", req, &wallet)
+	if err != nil {
+		return err
+	}
+	return a.print(raw, walletTable(wallet))
+}
+
+// walletFreeze замораживает кошелёк: wallet freeze ADDRESS
+//
+// POST /api/v2/admin/wallets/{address}/freeze
+func (a *app) walletFreeze(ctx context.Context, args []string) error {
+	return a.setWalletFrozen(ctx, args, http.MethodPost)
+}
+
+// walletUnfreeze снимает заморозку с кошелька: wallet unfreeze ADDRESS
+//
+// DELETE /api/v2/admin/wallets/{address}/freeze
+func (a *app) walletUnfreeze(ctx context.Context, args []string) error {
+	return a.setWalletFrozen(ctx, args, http.MethodDelete)
+}
+
+// walletAdjust выполняет ручную корректировку баланса: wallet adjust -address A -amount -12.50 -reason-code CODE -note TEXT
+//
+// Положительная сумма начисляется на кошелёк, отрицательная списывается с него.
+//
+// POST /api/v2/admin/wallets/{address}/adjustments
+func (a *app) walletAdjust(ctx context.Context, args []string) error {
+	var req dto.AdjustmentRequestV2
+	var address, amount string
+	fs := flag.NewFlagSet("wallet adjust", flag.ContinueOnError)
+	fs.StringVar(&address, "address", "", "wallet address")
+	fs.StringVar(&amount, "amount", "", "amount to credit, negative to debit, e.g. -12.50")
+	fs.StringVar(&req.ReasonCode, "reason-code", "", "reason code, e.g. goodwill or error_correction")
+	fs.StringVar(&req.Note, "note", "", "free-text note")
+	if err := a.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if address == "" || amount == "" || fs.NArg() != 0 {
+		return fmt.Errorf("%w: wallet adjust requires -address, -amount, -reason-code and -note", errUsage)
+	}
+
+	var err error
+	if req.Amount, err = dto.ParseAmount(amount); err != nil {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+	req.Direction = "credit"
+	if req.Amount < 0 {
+		req.Direction, req.Amount = "debit", -req.Amount
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	var transaction dto.TransactionResponse
+	raw, err := c.call(ctx, http.MethodPost, "/admin/wallets/"+url.PathEscape(address)+"/adjustments", req, &transaction)
+	if err != nil {
+		return err
+	}
+	return a.print(raw, transactionsTable(transaction))
+}
+
+// setWalletFrozen отправляет запрос заморозки кошелька указанным методом
+func (a *app) setWalletFrozen(ctx context.Context, args []string, method string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	var wallet dto.WalletResponseV2
+	raw, err := c.call(ctx, method, "/admin/wallets/"+url.PathEscape(args[0])+"/freeze", nil, &wallet)
+	if err != nil {
+		return err
+	}
+	return a.print(raw, walletTable(wallet))
+}
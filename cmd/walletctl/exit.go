@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// Коды завершения walletctl
+const (
+	exitOK                = 0  // Команда выполнена
+	exitError             = 1  // Внутренняя ошибка сервера или клиента
+	exitUsage             = 2  // Неверные команда, флаги или аргументы
+	exitUnavailable       = 3  // Сервер недоступен или ответил не в формате API
+	exitUnauthenticated   = 4  // Ключ доступа не передан, не существует или отозван
+	exitForbidden         = 5  // Недостаточно прав для операции или кошелька
+	exitNotFound          = 6  // Кошелёк, транзакция или другой объект не найден
+	exitInvalid           = 7  // Запрос отклонён проверкой (сумма, подпись, параметры)
+	exitInsufficientFunds = 8  // Недостаточно средств
+	exitFrozen            = 9  // Кошелёк заморожен
+	exitConflict          = 10 // Объект уже существует или уже изменён
+	exitRateLimited       = 11 // Превышен лимит частоты запросов
+)
+
+// errorExitCodes сопоставляет коды ошибок API с кодами завершения;
+// коды вида "*_not_found" соответствуют exitNotFound
+var errorExitCodes = map[string]int{
+	"missing_credentials":    exitUnauthenticated,
+	"invalid_api_key":        exitUnauthenticated,
+	"invalid_token":          exitUnauthenticated,
+	"unauthenticated":        exitUnauthenticated,
+	"forbidden":              exitForbidden,
+	"role_required":          exitForbidden,
+	"invalid_request":        exitInvalid,
+	"invalid_amount":         exitInvalid,
+	"self_transfer":          exitInvalid,
+	"signature_required":     exitInvalid,
+	"invalid_signature":      exitInvalid,
+	"invalid_nonce":          exitInvalid,
+	"invalid_public_key":     exitInvalid,
+	"reason_required":        exitInvalid,
+	"invalid_reason_code":    exitInvalid,
+	"note_too_long":          exitInvalid,
+	"system_wallet":          exitInvalid,
+	"invalid_external_id":    exitInvalid,
+	"memo_too_long":          exitInvalid,
+	"invalid_reference":      exitInvalid,
+	"invalid_metadata":       exitInvalid,
+	"invalid_status":         exitInvalid,
+	"not_enough_money":       exitInsufficientFunds,
+	"wallet_frozen":          exitFrozen,
+	"wallet_exists":          exitConflict,
+	"public_key_already_set": exitConflict,
+	"payment_exists":         exitConflict,
+	"reference_exists":       exitConflict,
+	"rate_limited":           exitRateLimited,
+}
+
+// exitCode возвращает код завершения для ошибки команды
+func exitCode(err error) int {
+	var apiErr *apiError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage), errors.Is(err, errFlags):
+		return exitUsage
+	case errors.Is(err, errUnavailable):
+		return exitUnavailable
+	case errors.As(err, &apiErr):
+		if code, ok := errorExitCodes[apiErr.Code]; ok {
+			return code
+		}
+		if strings.HasSuffix(apiErr.Code, "_not_found") {
+			return exitNotFound
+		}
+		return exitError
+	default:
+		return exitError
+	}
+}
@@ -0,0 +1,176 @@
+// Package main - walletctl, клиент командной строки для REST API платёжной системы
+//
+// Использование:
+//
+//	walletctl [-profile name] [-server url] [-api-key key] [-output table|json] <команда> [аргументы]
+//
+// Команды:
+//   - send -from A -to B -amount 33.30 [-nonce N -signature HEX | -nonce N -key FILE] [-memo TEXT -reference REF -meta KEY=VALUE]  — перевод средств
+//   - balance ADDRESS  — баланс кошелька
+//   - tx list [-count N] [-reference REF] [-status STATUS]  — последние транзакции, в том числе по внешнему идентификатору и состоянию
+//   - tx get ID  — транзакция по идентификатору
+//   - wallet create [-public-key HEX]  — создание кошелька (роль admin)
+//   - wallet freeze ADDRESS, wallet unfreeze ADDRESS  — заморозка кошелька и её снятие (роль admin)
+//   - wallet adjust -address A -amount -12.50 -reason-code CODE -note TEXT  — ручная корректировка баланса (роль admin)
+//   - profile set NAME -server URL [-api-key KEY], profile use NAME, profile list  — профили подключения
+//
+// Адрес сервера и ключ доступа берутся из флагов, переменных окружения WALLETCTL_SERVER и WALLETCTL_API_KEY
+// или из профиля в файле конфигурации (по умолчанию ~/.config/walletctl/config.yaml).
+// Клиент работает с /api/v2: суммы передаются строками без потери точности.
+//
+// Код завершения отражает код ошибки API (см. exitCode), что позволяет использовать walletctl в скриптах
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// usage - краткая справка по командам
+const usage = `usage: walletctl [flags] <command> [args]
+
+commands:
+  send -from ADDRESS -to ADDRESS -amount AMOUNT [-nonce N -signature HEX | -nonce N -key FILE] [-memo TEXT] [-reference REF] [-meta KEY=VALUE]...
+  balance ADDRESS
+  tx list [-count N] [-reference REF] [-status STATUS]
+  tx get ID
+  wallet create [-public-key HEX]
+  wallet freeze ADDRESS
+  wallet unfreeze ADDRESS
+  wallet adjust -address ADDRESS -amount AMOUNT -reason-code CODE -note TEXT
+  profile set NAME -server URL [-api-key KEY]
+  profile use NAME
+  profile list
+
+flags:
+`
+
+// Ошибки разбора командной строки
+var (
+	errUsage = errors.New("invalid usage") // Ошибка: команда или её аргументы заданы неверно
+	errFlags = errors.New("invalid flags") // Ошибка: флаги команды заданы неверно; сообщение уже выведено пакетом flag
+)
+
+// app содержит глобальные параметры запуска и потоки вывода
+type app struct {
+	configPath string
+	profile    string
+	server     string
+	apiKey     string
+	output     string
+	timeout    time.Duration
+
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run разбирает глобальные флаги, выполняет команду и возвращает код завершения процесса
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	a := &app{stdout: stdout, stderr: stderr}
+
+	fs := flag.NewFlagSet("walletctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&a.configPath, "config", envOr("WALLETCTL_CONFIG", defaultConfigPath()), "path to profiles file")
+	fs.StringVar(&a.profile, "profile", os.Getenv("WALLETCTL_PROFILE"), "profile name (default: current profile)")
+	fs.StringVar(&a.server, "server", os.Getenv("WALLETCTL_SERVER"), "server URL, overrides profile")
+	fs.StringVar(&a.apiKey, "api-key", os.Getenv("WALLETCTL_API_KEY"), "API key, overrides profile")
+	fs.StringVar(&a.output, "output", "table", "output format: table or json")
+	fs.DurationVar(&a.timeout, "timeout", 30*time.Second, "request timeout")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if a.output != outputTable && a.output != outputJSON {
+		fmt.Fprintf(stderr, "unknown output format %q\n", a.output)
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	err := a.dispatch(ctx, fs.Arg(0), fs.Args()[1:])
+	switch {
+	case err == nil, errors.Is(err, errFlags):
+	case err == errUsage:
+		fs.Usage()
+	default:
+		fmt.Fprintln(stderr, "error:", err)
+	}
+	return exitCode(err)
+}
+
+// dispatch выполняет команду верхнего уровня
+func (a *app) dispatch(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "send":
+		return a.send(ctx, args)
+	case "balance":
+		return a.balance(ctx, args)
+	case "tx":
+		return a.subcommand(ctx, args, map[string]func(context.Context, []string) error{
+			"list": a.txList,
+			"get":  a.txGet,
+		})
+	case "wallet":
+		return a.subcommand(ctx, args, map[string]func(context.Context, []string) error{
+			"create":   a.walletCreate,
+			"freeze":   a.walletFreeze,
+			"unfreeze": a.walletUnfreeze,
+			"adjust":   a.walletAdjust,
+		})
+	case "profile":
+		return a.subcommand(ctx, args, map[string]func(context.Context, []string) error{
+			"set":  a.profileSet,
+			"use":  a.profileUse,
+			"list": a.profileList,
+		})
+	default:
+		return errUsage
+	}
+}
+
+// subcommand выполняет подкоманду из таблицы commands
+func (a *app) subcommand(ctx context.Context, args []string, commands map[string]func(context.Context, []string) error) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return errUsage
+	}
+	return cmd(ctx, args[1:])
+}
+
+// parseFlags разбирает флаги команды
+func (a *app) parseFlags(fs *flag.FlagSet, args []string) error {
+	fs.SetOutput(a.stderr)
+	if err := fs.Parse(args); err != nil {
+		return errFlags
+	}
+	return nil
+}
+
+// envOr возвращает значение переменной окружения или значение по умолчанию
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
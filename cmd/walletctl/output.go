@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Форматы вывода
+const (
+	outputTable = "table" // Таблица для чтения человеком
+	outputJSON  = "json"  // Ответ API без изменений, с отступами
+)
+
+// print выводит ответ API: в формате json - тело ответа с отступами, в формате table - таблицу,
+// формируемую функцией table
+func (a *app) print(raw []byte, table func(w io.Writer)) error {
+	if a.output == outputJSON {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, raw, "", "  "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err := buf.WriteTo(a.stdout)
+		return err
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// transactionsTable выводит таблицу транзакций
+func transactionsTable(transactions ...dto.TransactionResponse) func(w io.Writer) {
+	return func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tTYPE\tSTATUS\tFROM\tTO\tAMOUNT\tCREATED AT")
+		for _, t := range transactions {
+			status := t.Status
+			if t.ErrorCode != "" {
+				status += " (" + t.ErrorCode + ")"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.Type, status, t.From, t.To, t.Amount, t.CreatedAt.Local().Format(time.DateTime))
+		}
+	}
+}
+
+// walletTable выводит таблицу с кошельком
+func walletTable(wallet dto.WalletResponseV2) func(w io.Writer) {
+	return func(w io.Writer) {
+		key := wallet.PublicKey
+		if key == "" {
+			key = "-"
+		}
+		fmt.Fprintln(w, "ADDRESS\tBALANCE\tFROZEN\tNONCE\tPUBLIC KEY")
+		fmt.Fprintf(w, "%s\t%s\t%t\t%d\t%s\n", wallet.Address, wallet.Balance, wallet.Frozen, wallet.Nonce, key)
+	}
+}
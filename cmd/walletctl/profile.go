@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+)
+
+// defaultProfile - имя профиля, используемого, если текущий профиль не выбран
+const defaultProfile = "default"
+
+// profilesFile - файл конфигурации walletctl с профилями подключения
+//
+// Пример:
+//
+//	current: prod
+//	profiles:
+//	  prod:
+//	    server: https://wallet.example.com
+//	    api_key: "..."
+type profilesFile struct {
+	Current  string             `yaml:"current,omitempty"`
+	Profiles map[string]profile `yaml:"profiles"`
+}
+
+// profile - параметры подключения к серверу
+type profile struct {
+	Server string `yaml:"server"`
+	APIKey string `yaml:"api_key,omitempty"`
+}
+
+// defaultConfigPath возвращает путь к файлу профилей в каталоге конфигурации пользователя
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "walletctl.yaml"
+	}
+	return filepath.Join(dir, "walletctl", "config.yaml")
+}
+
+// loadProfiles читает файл профилей; отсутствующий файл соответствует пустому списку профилей
+func loadProfiles(path string) (*profilesFile, error) {
+	f := &profilesFile{Profiles: map[string]profile{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]profile{}
+	}
+	return f, nil
+}
+
+// save записывает файл профилей; файл содержит ключи доступа, поэтому доступен только владельцу
+func (f *profilesFile) save(path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// client создаёт клиента API по флагам, переменным окружения и профилю (в порядке приоритета)
+func (a *app) client() (*client, error) {
+	f, err := loadProfiles(a.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	name := a.profile
+	if name == "" {
+		name = f.Current
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+	p, ok := f.Profiles[name]
+	if !ok && a.profile != "" {
+		return nil, fmt.Errorf("%w: profile %q not found in %s", errUsage, name, a.configPath)
+	}
+
+	if a.server != "" {
+		p.Server = a.server
+	}
+	if a.apiKey != "" {
+		p.APIKey = a.apiKey
+	}
+	if p.Server == "" {
+		return nil, fmt.Errorf("%w: server URL is not set, use -server or `walletctl profile set`", errUsage)
+	}
+	return &client{server: p.Server, apiKey: p.APIKey, http: &http.Client{Timeout: a.timeout}}, nil
+}
+
+// profileSet создаёт или изменяет профиль: profile set NAME -server URL [-api-key KEY]
+func (a *app) profileSet(_ context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	name := args[0]
+
+	fs := flag.NewFlagSet("profile set", flag.ContinueOnError)
+	server := fs.String("server", "", "server URL, e.g. http://localhost:8080")
+	apiKey := fs.String("api-key", "", "API key")
+	if err := a.parseFlags(fs, args[1:]); err != nil {
+		return err
+	}
+
+	f, err := loadProfiles(a.configPath)
+	if err != nil {
+		return err
+	}
+	p := f.Profiles[name]
+	if *server != "" {
+		p.Server = *server
+	}
+	if *apiKey != "" {
+		p.APIKey = *apiKey
+	}
+	if p.Server == "" {
+		return fmt.Errorf("%w: -server is required for a new profile", errUsage)
+	}
+	f.Profiles[name] = p
+	if f.Current == "" {
+		f.Current = name
+	}
+	if err = f.save(a.configPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "profile %q saved to %s\n", name, a.configPath)
+	return nil
+}
+
+// profileUse выбирает текущий профиль: profile use NAME
+func (a *app) profileUse(_ context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	f, err := loadProfiles(a.configPath)
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Profiles[args[0]]; !ok {
+		return fmt.Errorf("%w: profile %q not found in %s", errUsage, args[0], a.configPath)
+	}
+	f.Current = args[0]
+	if err = f.save(a.configPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "current profile: %s\n", args[0])
+	return nil
+}
+
+// profileList выводит профили; ключи доступа не выводятся
+func (a *app) profileList(_ context.Context, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	f, err := loadProfiles(a.configPath)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENT\tNAME\tSERVER\tAPI KEY")
+	for _, name := range names {
+		current, key := "", "-"
+		if name == f.Current {
+			current = "*"
+		}
+		if f.Profiles[name].APIKey != "" {
+			key = "set"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", current, name, f.Profiles[name].Server, key)
+	}
+	return w.Flush()
+}
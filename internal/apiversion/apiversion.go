@@ -0,0 +1,89 @@
+// Package apiversion определяет версии HTTP API и формат ответов с ошибками каждой версии
+package apiversion
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version - версия HTTP API
+type Version int
+
+// Поддерживаемые версии API
+const (
+	V1 Version = 1 // Суммы числами в у.е., ошибки {"error": "<сообщение>"}
+	V2 Version = 2 // Суммы строками с двумя знаками после запятой, ошибки {"error": {"code": ..., "message": ...}}
+)
+
+// versionKey - ключ версии API в контексте Gin
+const versionKey = "api_version"
+
+// Use сохраняет версию API в контексте запроса; должен стоять первым в группе маршрутов версии,
+// чтобы ошибки аутентификации и ограничения частоты запросов возвращались в формате версии
+func Use(v Version) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(versionKey, v)
+	}
+}
+
+// Get возвращает версию API запроса; по умолчанию V1
+func Get(c *gin.Context) Version {
+	if v, ok := c.Get(versionKey); ok {
+		return v.(Version)
+	}
+	return V1
+}
+
+// Deprecate помечает ответы устаревшей версии API заголовками
+//
+// Параметры:
+//   - deprecatedAt (time.Time): дата объявления версии устаревшей, заголовок `Deprecation: @<unix-время>` (RFC 9745)
+//   - sunset (time.Time): дата отключения версии, заголовок `Sunset` (RFC 8594); не отправляется, если нулевая
+//
+// Заголовок `Link` с rel="successor-version" указывает тот же ресурс в /api/v2
+func Deprecate(deprecatedAt, sunset time.Time) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("Deprecation", deprecation)
+		if !sunset.IsZero() {
+			h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		h.Set("Link", "</api/v2"+resourcePath(c.Request.URL.Path)+`>; rel="successor-version"`)
+	}
+}
+
+// resourcePath возвращает путь ресурса без префикса /api или /api/v1
+func resourcePath(path string) string {
+	path = strings.TrimPrefix(path, "/api")
+	if rest, ok := strings.CutPrefix(path, "/v1"); ok && (rest == "" || rest[0] == '/') {
+		return rest
+	}
+	return path
+}
+
+// Error отправляет ответ с ошибкой в формате версии API запроса
+//
+// Параметры:
+//   - status (int): HTTP-статус
+//   - code (string): машиночитаемый код ошибки (передаётся только в V2)
+//   - message (string): описание ошибки
+func Error(c *gin.Context, status int, code, message string) {
+	c.JSON(status, body(c, code, message))
+}
+
+// Abort прерывает обработку запроса и отправляет ответ с ошибкой в формате версии API запроса
+func Abort(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, body(c, code, message))
+}
+
+// body формирует тело ответа с ошибкой
+func body(c *gin.Context, code, message string) gin.H {
+	if Get(c) == V1 {
+		return gin.H{"error": message}
+	}
+	return gin.H{"error": gin.H{"code": code, "message": message}}
+}
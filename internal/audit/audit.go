@@ -0,0 +1,111 @@
+// Package audit записывает журнал аудита в той же транзакции базы данных, что и само изменение
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"gorm.io/gorm"
+)
+
+// Действия, записываемые в журнал аудита
+const (
+	ActionTransferCreate   = "transfer.create"     // Перевод средств между кошельками
+	ActionTransferFail     = "transfer.fail"       // Отклонённая попытка перевода
+	ActionWalletCreate     = "wallet.create"       // Создание кошелька
+	ActionWalletKeySet     = "wallet.key_register" // Регистрация публичного ключа кошелька
+	ActionWalletFreeze     = "wallet.freeze"       // Заморозка кошелька
+	ActionWalletUnfreeze   = "wallet.unfreeze"     // Снятие заморозки с кошелька
+	ActionAdjustmentCreate = "adjustment.create"   // Ручная корректировка баланса через кошелёк капитала
+	ActionPaymentCreate    = "payment.create"      // Создание пополнения или вывода средств
+	ActionPaymentComplete  = "payment.complete"    // Подтверждение пополнения или вывода платёжным провайдером
+	ActionPaymentFail      = "payment.fail"        // Отказ платёжного провайдера в пополнении или выводе
+	ActionPaymentTransfer  = "payment.transfer"    // Проводка пополнения, вывода или возврата через казначейство
+	ActionAPIKeyCreate     = "api_key.create"      // Создание ключа доступа
+	ActionAPIKeyWalletsSet = "api_key.wallets_set" // Замена кошельков ключа доступа
+	ActionAPIKeyRevoke     = "api_key.revoke"      // Отзыв ключа доступа
+	ActionWebhookCreate    = "webhook.create"      // Создание подписки на вебхуки
+	ActionWebhookDelete    = "webhook.delete"      // Удаление подписки на вебхуки
+	ActionWebhookRedeliver = "webhook.redeliver"   // Ручная повторная доставка события вебхука
+)
+
+// Типы сущностей в журнале аудита
+const (
+	EntityTransaction  = "transaction"
+	EntityWallet       = "wallet"
+	EntityAPIKey       = "api_key"
+	EntityWebhook      = "webhook"
+	EntityWebhookEvent = "webhook_event"
+	EntityPayment      = "payment"
+)
+
+// SystemActor - субъект действий, выполненных без HTTP-запроса (инициализация, фоновые задачи, CLI)
+const SystemActor = "system"
+
+// Meta содержит сведения о том, кто и откуда выполнил действие
+type Meta struct {
+	Actor     string
+	RequestID string
+	SourceIP  string
+}
+
+// metaKey - ключ сведений аудита в контексте
+type metaKey struct{}
+
+// WithMeta сохраняет сведения аудита в контексте
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// MetaFromContext возвращает сведения аудита из контекста; если их нет, субъектом считается "system"
+func MetaFromContext(ctx context.Context) Meta {
+	meta, ok := ctx.Value(metaKey{}).(Meta)
+	if !ok || meta.Actor == "" {
+		meta.Actor = SystemActor
+	}
+	return meta
+}
+
+// Record добавляет запись в журнал аудита
+//
+// Параметры:
+//   - tx (*gorm.DB): транзакция, в которой выполняется изменение; субъект берётся из её контекста
+//   - action (string): действие
+//   - entityType (string): тип изменённой сущности
+//   - entityID (string): идентификатор изменённой сущности
+//   - before (any): состояние до изменения; nil для созданных сущностей
+//   - after (any): состояние после изменения
+//
+// Возвращает:
+//   - error: ошибку сериализации состояния или записи; транзакция изменения должна быть отменена
+func Record(tx *gorm.DB, action, entityType, entityID string, before, after any) error {
+	meta := MetaFromContext(tx.Statement.Context)
+
+	entry := models.AuditLog{
+		Actor:      meta.Actor,
+		RequestID:  meta.RequestID,
+		SourceIP:   meta.SourceIP,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+
+	var err error
+	if entry.Before, err = marshal(before); err != nil {
+		return fmt.Errorf("audit %s: marshal before: %w", action, err)
+	}
+	if entry.After, err = marshal(after); err != nil {
+		return fmt.Errorf("audit %s: marshal after: %w", action, err)
+	}
+
+	return tx.Create(&entry).Error
+}
+
+// marshal сериализует состояние в JSON; nil остаётся NULL
+func marshal(state any) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	return json.Marshal(state)
+}
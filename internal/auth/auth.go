@@ -0,0 +1,145 @@
+// Package auth описывает субъекта запроса и его права на операции с кошельками
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"slices"
+)
+
+// keyPrefix - префикс всех выдаваемых ключей доступа к API
+const keyPrefix = "wk_"
+
+// Роли субъектов запроса; каждая следующая роль включает права предыдущей
+const (
+	RoleViewer   = "viewer"   // Чтение балансов и истории
+	RoleOperator = "operator" // Чтение и переводы средств
+	RoleAdmin    = "admin"    // Все операции, включая управление ключами и кошельками
+)
+
+// roleLevels задаёт порядок ролей
+var roleLevels = map[string]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// ValidRole сообщает, является ли строка известной ролью
+func ValidRole(role string) bool {
+	_, ok := roleLevels[role]
+	return ok
+}
+
+// Principal описывает аутентифицированного субъекта запроса
+//
+// Поля:
+//   - Subject (string) — идентификатор субъекта (например, "api_key:12" или "jwt:alice")
+//   - Name (string) — человекочитаемое имя субъекта
+//   - Role (string) — роль субъекта: viewer, operator или admin; пусто - нет прав
+//   - Wallets ([]string) — кошельки, с которых субъект может списывать средства и чьи данные может читать
+//   - AllWallets (bool) — субъект может работать с любыми кошельками (сотрудники back-office,
+//     выключенная аутентификация)
+type Principal struct {
+	Subject    string
+	Name       string
+	Role       string
+	Wallets    []string
+	AllWallets bool
+}
+
+// Anonymous - субъект запросов при выключенной аутентификации, которому разрешены все операции
+var Anonymous = &Principal{Subject: "anonymous", Name: "anonymous", Role: RoleAdmin, AllWallets: true}
+
+// NewAPIKeyPrincipal создаёт субъекта для ключа доступа: административный ключ получает роль admin,
+// остальные - operator с ограничением привязанными кошельками
+func NewAPIKeyPrincipal(key *models.APIKey) *Principal {
+	role := RoleOperator
+	if key.Admin {
+		role = RoleAdmin
+	}
+	return &Principal{
+		Subject: fmt.Sprintf("api_key:%d", key.ID),
+		Name:    key.Name,
+		Role:    role,
+		Wallets: key.Addresses(),
+	}
+}
+
+// HasRole сообщает, есть ли у субъекта указанная роль или роль выше неё
+func (p *Principal) HasRole(role string) bool {
+	level, ok := roleLevels[p.Role]
+	return ok && level >= roleLevels[role]
+}
+
+// CanDebit сообщает, может ли субъект списывать средства с кошелька
+func (p *Principal) CanDebit(address string) bool {
+	return p.AllWallets || slices.Contains(p.Wallets, address)
+}
+
+// CanRead сообщает, может ли субъект читать баланс и историю кошелька
+func (p *Principal) CanRead(address string) bool {
+	return p.ReadsAll() || slices.Contains(p.Wallets, address)
+}
+
+// ReadsAll сообщает, может ли субъект читать данные всех кошельков
+func (p *Principal) ReadsAll() bool {
+	return p.Role == RoleAdmin || p.AllWallets
+}
+
+// ReadableWallets возвращает кошельки, данные которых может читать субъект; nil - все кошельки
+func (p *Principal) ReadableWallets() []string {
+	if p.ReadsAll() {
+		return nil
+	}
+	if p.Wallets == nil {
+		return []string{}
+	}
+	return p.Wallets
+}
+
+// principalKey - ключ субъекта запроса в контексте
+type principalKey struct{}
+
+// WithPrincipal сохраняет субъекта запроса в контексте
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext возвращает субъекта запроса из контекста
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// GenerateKey создаёт новый ключ доступа к API
+//
+// Возвращает:
+//   - string: ключ вида "wk_<64 hex-символа>", показывается владельцу один раз
+//   - string: префикс ключа для опознания в списках
+func GenerateKey() (string, string) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand: " + err.Error())
+	}
+	key := keyPrefix + hex.EncodeToString(b)
+	return key, KeyPrefix(key)
+}
+
+// KeyPrefix возвращает первые символы ключа, по которым его можно опознать без раскрытия
+func KeyPrefix(key string) string {
+	const n = len(keyPrefix) + 8
+	if len(key) < n {
+		return key
+	}
+	return key[:n]
+}
+
+// HashKey возвращает SHA-256 хеш ключа в hex, под которым ключ хранится в базе данных
+func HashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
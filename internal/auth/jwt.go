@@ -0,0 +1,284 @@
+// Package auth описывает субъекта запроса и его права на операции с кошельками
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// ErrInvalidToken - ошибка: токен не прошёл проверку подписи или утверждений
+var ErrInvalidToken = errors.New("invalid token")
+
+// asymmetricAlgorithms - алгоритмы подписи, допустимые для открытых ключей из JWKS или PEM
+var asymmetricAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// hmacAlgorithms - алгоритмы подписи, допустимые для общего секрета
+var hmacAlgorithms = []string{"HS256", "HS384", "HS512"}
+
+// JWTVerifier проверяет JWT и сопоставляет утверждения токена с ролями
+type JWTVerifier struct {
+	keys       map[string]any // Ключи из JWKS по идентификатору "kid"
+	defaultKey any            // Ключ для токенов без "kid" (статический ключ или единственный ключ JWKS)
+	parser     *jwt.Parser
+	rolesClaim string
+	roleMap    map[string]string
+}
+
+// NewJWTVerifier создаёт проверку JWT по настройкам `auth.jwt`
+//
+// Параметры:
+//   - cfg (*config.JWTConfig): настройки проверки токенов
+//
+// Возвращает:
+//   - *JWTVerifier: проверка токенов
+//   - error: ошибку чтения JWKS-файла или разбора статического ключа
+//
+// Логика работы:
+//  1. Загрузка ключей из JWKS-файла или разбор статического ключа (PEM открытый ключ или общий секрет HMAC)
+//  2. Ограничение допустимых алгоритмов подписи типом ключа, чтобы исключить подмену алгоритма
+//  3. Настройка проверки iss, aud и обязательного exp
+func NewJWTVerifier(cfg *config.JWTConfig) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		keys:       make(map[string]any),
+		rolesClaim: cfg.RolesClaim,
+		roleMap:    cfg.RoleMapping,
+	}
+
+	algorithms := asymmetricAlgorithms
+	switch {
+	case cfg.JWKSFile != "":
+		if err := v.loadJWKS(cfg.JWKSFile); err != nil {
+			return nil, fmt.Errorf("load jwks %s: %w", cfg.JWKSFile, err)
+		}
+	case strings.HasPrefix(strings.TrimSpace(cfg.StaticKey), "-----BEGIN"):
+		key, err := parsePEMPublicKey(cfg.StaticKey)
+		if err != nil {
+			return nil, fmt.Errorf("parse static key: %w", err)
+		}
+		v.defaultKey = key
+	default:
+		v.defaultKey = []byte(cfg.StaticKey)
+		algorithms = hmacAlgorithms
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(algorithms), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+
+	return v, nil
+}
+
+// Verify проверяет токен и возвращает субъекта с ролью из утверждений токена
+//
+// Сотрудникам back-office доступны все кошельки, поэтому AllWallets = true; операции ограничиваются ролью.
+//
+// Возвращает:
+//   - *Principal: субъект с наивысшей из сопоставленных ролей (пустая роль, если ни одна не сопоставлена)
+//   - error: ErrInvalidToken, если подпись или утверждения токена некорректны
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	sub, _ := claims.GetSubject()
+	name := sub
+	for _, claim := range []string{"preferred_username", "email"} {
+		if value, ok := claims[claim].(string); ok && value != "" {
+			name = value
+			break
+		}
+	}
+
+	return &Principal{
+		Subject:    "jwt:" + sub,
+		Name:       name,
+		Role:       v.role(claims),
+		AllWallets: true,
+	}, nil
+}
+
+// keyFunc выбирает ключ проверки подписи по заголовку "kid"
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (any, error) {
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		if key, ok := v.keys[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if v.defaultKey == nil {
+		return nil, errors.New("token has no key id")
+	}
+	return v.defaultKey, nil
+}
+
+// role возвращает наивысшую роль, сопоставленную значениям утверждения `roles_claim`
+//
+// Утверждение может быть строкой, строкой с ролями через пробел или массивом строк;
+// путь к вложенному утверждению задаётся через точку (например, "realm_access.roles")
+func (v *JWTVerifier) role(claims jwt.MapClaims) string {
+	var value any = map[string]any(claims)
+	for _, part := range strings.Split(v.rolesClaim, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return ""
+		}
+		value = m[part]
+	}
+
+	var values []string
+	switch val := value.(type) {
+	case string:
+		values = strings.Fields(val)
+	case []any:
+		for _, item := range val {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+
+	best := ""
+	for _, claimValue := range values {
+		role := claimValue
+		if len(v.roleMap) > 0 {
+			role = v.roleMap[claimValue]
+		}
+		if ValidRole(role) && (best == "" || roleLevels[role] > roleLevels[best]) {
+			best = role
+		}
+	}
+	return best
+}
+
+// jwk описывает открытый ключ в формате JSON Web Key
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	Use string `json:"use"`
+}
+
+// loadJWKS загружает открытые ключи RSA, EC и Ed25519 из JWKS-файла
+func (v *JWTVerifier) loadJWKS(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return fmt.Errorf("key %d (kid %q): %w", i, k.Kid, err)
+		}
+		v.keys[k.Kid] = key
+	}
+
+	if len(v.keys) == 0 {
+		return errors.New("no signing keys")
+	}
+	if len(v.keys) == 1 {
+		for _, key := range v.keys {
+			v.defaultKey = key
+		}
+	}
+	return nil
+}
+
+// publicKey преобразует JWK в открытый ключ crypto
+func (k *jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeBigInt декодирует число из base64url без выравнивания
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// parsePEMPublicKey разбирает открытый ключ в формате PEM (PKIX)
+func parsePEMPublicKey(value string) (any, error) {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
@@ -3,20 +3,63 @@
 package config
 
 import (
+	"fmt"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"time"
 )
 
+// DefaultPath - путь к файлу-конфигурации по умолчанию
+const DefaultPath = "internal/config/config.yaml"
+
 // Config содержит настройки сервера и базы данных
 type Config struct {
-	Server   ServerConfig   // Конфигурация HTTP сервера
-	Database DatabaseConfig // Конфигурация базы данных
+	Server    ServerConfig    // Конфигурация HTTP сервера
+	Database  DatabaseConfig  // Конфигурация базы данных
+	Tracing   TracingConfig   // Конфигурация трассировки OpenTelemetry
+	Log       LogConfig       // Конфигурация логирования
+	Auth      AuthConfig      // Конфигурация аутентификации
+	RateLimit RateLimitConfig // Конфигурация ограничения частоты запросов
+	Stream    StreamConfig    // Конфигурация потоковых API
+	Webhooks  WebhooksConfig  // Конфигурация доставки вебхуков
+	GRPC      GRPCConfig      // Конфигурация gRPC сервера
+	OpenAPI   OpenAPIConfig   // Конфигурация проверки запросов по OpenAPI-описанию
+	API       APIConfig       // Конфигурация версий HTTP API
+	Seed      SeedConfig      // Конфигурация начального заполнения базы данных
+	Payments  PaymentsConfig  // Конфигурация пополнений и выводов через платёжного провайдера
 }
 
 // ServerConfig содержит настройки HTTP сервера.
 type ServerConfig struct {
 	// Address - адрес сервера (по умолчанию: "localhost:8080")
 	Address string `yaml:"address" env-default:"localhost:8080"`
+	// ReadTimeout - максимальное время чтения запроса целиком, включая тело (по умолчанию: 10s)
+	ReadTimeout time.Duration `yaml:"read_timeout" mapstructure:"read_timeout"`
+	// ReadHeaderTimeout - максимальное время чтения заголовков запроса (по умолчанию: 5s)
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" mapstructure:"read_header_timeout"`
+	// WriteTimeout - максимальное время записи ответа (по умолчанию: 15s)
+	WriteTimeout time.Duration `yaml:"write_timeout" mapstructure:"write_timeout"`
+	// IdleTimeout - время жизни неактивного keep-alive соединения (по умолчанию: 60s)
+	IdleTimeout time.Duration `yaml:"idle_timeout" mapstructure:"idle_timeout"`
+	// DrainDelay - задержка между переводом /readyz в состояние "fail" и остановкой приёма соединений,
+	// чтобы балансировщик успел исключить экземпляр (по умолчанию: 0s)
+	DrainDelay time.Duration `yaml:"drain_delay" mapstructure:"drain_delay"`
+	// ShutdownTimeout - время на завершение обрабатываемых запросов при остановке сервера (по умолчанию: 20s)
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" mapstructure:"shutdown_timeout"`
+	// MaxBodyBytes - максимальный размер тела запроса, которое промежуточные обработчики читают целиком
+	// до обработчика маршрута (по умолчанию: 1 МиБ)
+	MaxBodyBytes int64 `yaml:"max_body_bytes" mapstructure:"max_body_bytes"`
+	// TrustedProxies - IP-адреса и CIDR-сети обратных прокси, которым разрешено передавать адрес клиента
+	// в заголовке X-Forwarded-For; пусто - адрес клиента берётся из соединения (по умолчанию: пусто)
+	TrustedProxies []string `yaml:"trusted_proxies" mapstructure:"trusted_proxies"`
+}
+
+// GRPCConfig содержит настройки gRPC сервера
+type GRPCConfig struct {
+	// Enabled - запускать gRPC сервер (по умолчанию: false)
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Address - адрес gRPC сервера, отдельный от HTTP (по умолчанию: "localhost:9090")
+	Address string `yaml:"address"`
 }
 
 // DatabaseConfig содержит параметры подключения к базе данных
@@ -33,11 +76,206 @@ type DatabaseConfig struct {
 	DBName string `yaml:"dbname" env-default:"postgres"`
 	// SSLMode - режим SSL (по умолчанию: "disable")
 	SSlMode string `yaml:"sslmode" env-default:"disable"`
+
+	// MaxOpenConns - максимальное число открытых соединений в пуле, 0 - без ограничений (по умолчанию: 20)
+	MaxOpenConns int `yaml:"max_open_conns" mapstructure:"max_open_conns"`
+	// MaxIdleConns - максимальное число простаивающих соединений в пуле (по умолчанию: 10)
+	MaxIdleConns int `yaml:"max_idle_conns" mapstructure:"max_idle_conns"`
+	// ConnMaxLifetime - максимальное время жизни соединения, 0 - без ограничений (по умолчанию: 30m)
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" mapstructure:"conn_max_lifetime"`
+	// ConnMaxIdleTime - максимальное время простоя соединения, 0 - без ограничений (по умолчанию: 5m)
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" mapstructure:"conn_max_idle_time"`
+	// StatementTimeout - ограничение времени выполнения SQL-запроса, 0 - без ограничений (по умолчанию: 5s)
+	StatementTimeout time.Duration `yaml:"statement_timeout" mapstructure:"statement_timeout"`
+	// ConnectTimeout - таймаут установки одного соединения (по умолчанию: 5s)
+	ConnectTimeout time.Duration `yaml:"connect_timeout" mapstructure:"connect_timeout"`
+	// ConnectRetries - число повторных попыток подключения при старте (по умолчанию: 10)
+	ConnectRetries int `yaml:"connect_retries" mapstructure:"connect_retries"`
+	// RetryBackoff - начальная задержка между попытками подключения, удваивается после каждой попытки (по умолчанию: 500ms)
+	RetryBackoff time.Duration `yaml:"retry_backoff" mapstructure:"retry_backoff"`
+	// RetryMaxBackoff - максимальная задержка между попытками подключения (по умолчанию: 10s)
+	RetryMaxBackoff time.Duration `yaml:"retry_max_backoff" mapstructure:"retry_max_backoff"`
+}
+
+// TracingConfig содержит настройки трассировки OpenTelemetry
+type TracingConfig struct {
+	// Enabled - включает экспорт спанов (по умолчанию: false)
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Exporter - способ экспорта спанов: "otlp" или "file" (по умолчанию: "otlp")
+	Exporter string `yaml:"exporter"`
+	// Endpoint - адрес OTLP/HTTP коллектора в формате host:port (по умолчанию: "localhost:4318")
+	Endpoint string `yaml:"endpoint"`
+	// Insecure - отправка спанов в коллектор без TLS (по умолчанию: true)
+	Insecure bool `yaml:"insecure"`
+	// FilePath - путь к файлу для экспортёра "file" (по умолчанию: "traces.json")
+	FilePath string `yaml:"file_path" mapstructure:"file_path"`
+	// ServiceName - имя сервиса в спанах (по умолчанию: "wallet-api")
+	ServiceName string `yaml:"service_name" mapstructure:"service_name"`
+	// SampleRatio - доля трассируемых запросов от 0 до 1 (по умолчанию: 1)
+	SampleRatio float64 `yaml:"sample_ratio" mapstructure:"sample_ratio"`
+}
+
+// LogConfig содержит настройки логирования
+type LogConfig struct {
+	// Level - минимальный уровень логирования: debug, info, warn, error; пусто - уровень окружения ENV
+	Level string `yaml:"level"`
+	// Sampling - семплирование одинаковых записей
+	Sampling LogSamplingConfig `yaml:"sampling"`
+}
+
+// LogSamplingConfig содержит настройки семплирования логов
+type LogSamplingConfig struct {
+	// Enabled - включает семплирование (по умолчанию: false)
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Initial - число одинаковых записей в секунду, которые пишутся всегда (по умолчанию: 100)
+	Initial int `yaml:"initial"`
+	// Thereafter - после Initial пишется каждая Thereafter-я запись (по умолчанию: 100)
+	Thereafter int `yaml:"thereafter"`
+}
+
+// AuthConfig содержит настройки аутентификации по ключам доступа к API и JWT
+type AuthConfig struct {
+	// Enabled - требовать ключ доступа или JWT для всех запросов к /api (по умолчанию: true)
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// BootstrapAdminKey - административный ключ, создаваемый при старте, если его ещё нет в базе данных;
+	// пусто - ключ не создаётся
+	BootstrapAdminKey string `yaml:"bootstrap_admin_key" mapstructure:"bootstrap_admin_key"`
+	// JWT - проверка bearer-токенов сотрудников back-office
+	JWT JWTConfig `yaml:"jwt"`
+}
+
+// JWTConfig содержит настройки проверки JWT, выпущенных OIDC-провайдером
+type JWTConfig struct {
+	// Enabled - принимать токены в заголовке "Authorization: Bearer" (по умолчанию: false)
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// JWKSFile - путь к JWKS-файлу с открытыми ключами провайдера
+	JWKSFile string `yaml:"jwks_file" mapstructure:"jwks_file"`
+	// StaticKey - открытый ключ в формате PEM или общий секрет HMAC не короче 32 байт; используется, если JWKSFile не задан
+	StaticKey string `yaml:"static_key" mapstructure:"static_key"`
+	// Issuer - ожидаемое значение утверждения iss; пусто - не проверяется
+	Issuer string `yaml:"issuer"`
+	// Audience - ожидаемое значение утверждения aud; пусто - не проверяется
+	Audience string `yaml:"audience"`
+	// RolesClaim - утверждение с ролями, вложенность через точку (по умолчанию: "roles")
+	RolesClaim string `yaml:"roles_claim" mapstructure:"roles_claim"`
+	// RoleMapping - сопоставление значений утверждения ролям viewer, operator, admin;
+	// пусто - значения утверждения используются как роли
+	RoleMapping map[string]string `yaml:"role_mapping" mapstructure:"role_mapping"`
+}
+
+// RateLimitConfig содержит настройки ограничения частоты запросов
+type RateLimitConfig struct {
+	// Enabled - включает ограничение частоты запросов к /api (по умолчанию: true)
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Store - хранилище корзин: "memory" для одного экземпляра или "postgres" для нескольких (по умолчанию: "memory")
+	Store string `yaml:"store"`
+	// IP - ограничение по IP-адресу до аутентификации, в том числе для запросов с неверными учётными данными
+	IP LimitConfig `yaml:"ip"`
+	// Client - ограничение по клиенту: ключу доступа, субъекту JWT или IP-адресу
+	Client LimitConfig `yaml:"client"`
+	// Sender - ограничение переводов и выводов средств по адресу списываемого кошелька
+	Sender LimitConfig `yaml:"sender"`
+	// CleanupInterval - период удаления неиспользуемых корзин (по умолчанию: 1m)
+	CleanupInterval time.Duration `yaml:"cleanup_interval" mapstructure:"cleanup_interval"`
+	// IdleTTL - время, после которого неиспользуемая корзина удаляется (по умолчанию: 10m)
+	IdleTTL time.Duration `yaml:"idle_ttl" mapstructure:"idle_ttl"`
+}
+
+// LimitConfig содержит параметры корзины токенов
+type LimitConfig struct {
+	// Rate - скорость пополнения, запросов в секунду
+	Rate float64 `yaml:"rate"`
+	// Burst - максимальное число запросов подряд
+	Burst int `yaml:"burst"`
+}
+
+// StreamConfig содержит настройки потоковых API
+type StreamConfig struct {
+	// HeartbeatInterval - период отправки служебных сообщений, удерживающих соединение открытым (по умолчанию: 15s)
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval" mapstructure:"heartbeat_interval"`
+	// BufferSize - число событий в очереди одного клиента; при переполнении клиент отключается (по умолчанию: 256)
+	BufferSize int `yaml:"buffer_size" mapstructure:"buffer_size"`
+	// ReplayLimit - максимальное число пропущенных событий, отправляемых при переподключении (по умолчанию: 1000)
+	ReplayLimit int `yaml:"replay_limit" mapstructure:"replay_limit"`
+}
+
+// WebhooksConfig содержит настройки доставки исходящих вебхуков
+type WebhooksConfig struct {
+	// Enabled - включает фоновую доставку событий; при выключенной доставке события накапливаются (по умолчанию: true)
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// PollInterval - период выборки событий, ожидающих доставки (по умолчанию: 1s)
+	PollInterval time.Duration `yaml:"poll_interval" mapstructure:"poll_interval"`
+	// BatchSize - максимальное число событий, доставляемых за один проход (по умолчанию: 50)
+	BatchSize int `yaml:"batch_size" mapstructure:"batch_size"`
+	// Timeout - таймаут одного запроса к получателю (по умолчанию: 10s)
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxAttempts - число автоматических попыток, после которого событие считается недоставленным (по умолчанию: 10)
+	MaxAttempts int `yaml:"max_attempts" mapstructure:"max_attempts"`
+	// RetryBackoff - задержка перед второй попыткой, удваивается после каждой неудачи (по умолчанию: 10s)
+	RetryBackoff time.Duration `yaml:"retry_backoff" mapstructure:"retry_backoff"`
+	// RetryMaxBackoff - максимальная задержка между попытками (по умолчанию: 1h)
+	RetryMaxBackoff time.Duration `yaml:"retry_max_backoff" mapstructure:"retry_max_backoff"`
+}
+
+// OpenAPIConfig содержит настройки проверки HTTP-запросов и ответов по OpenAPI-описанию.
+// Описание относится к /api и /api/v1, запросы к /api/v2 не проверяются
+type OpenAPIConfig struct {
+	// ValidateRequests - отклонять с 400 запросы, не соответствующие описанию (по умолчанию: true)
+	ValidateRequests bool `yaml:"validate_requests" mapstructure:"validate_requests"`
+	// ValidateResponses - проверять ответы и заменять несоответствующие описанию на 500.
+	// Включается в тестах и на стенде: ответ буферизуется целиком (по умолчанию: false)
+	ValidateResponses bool `yaml:"validate_responses" mapstructure:"validate_responses"`
+}
+
+// APIConfig содержит настройки версий HTTP API
+type APIConfig struct {
+	// V1DeprecatedAt - дата объявления /api/v1 устаревшей в формате YYYY-MM-DD, заголовок Deprecation (по умолчанию: "2026-10-19")
+	V1DeprecatedAt string `yaml:"v1_deprecated_at" mapstructure:"v1_deprecated_at"`
+	// V1Sunset - дата отключения /api/v1 в формате YYYY-MM-DD, заголовок Sunset; пусто - дата не объявлена
+	V1Sunset string `yaml:"v1_sunset" mapstructure:"v1_sunset"`
+}
+
+// V1Dates возвращает даты объявления /api/v1 устаревшей и её отключения (нулевая, если не задана).
+// Значения должны быть проверены Validate
+func (a *APIConfig) V1Dates() (deprecatedAt, sunset time.Time) {
+	deprecatedAt, _ = time.Parse(time.DateOnly, a.V1DeprecatedAt)
+	if a.V1Sunset != "" {
+		sunset, _ = time.Parse(time.DateOnly, a.V1Sunset)
+	}
+	return deprecatedAt, sunset
+}
+
+// SeedConfig содержит настройки начального заполнения базы данных кошельками
+//
+// Заполнение выполняется, только если в базе данных ещё нет кошельков
+type SeedConfig struct {
+	// Enabled - заполнять базу данных при запуске сервера; в продакшене выключается (по умолчанию: true).
+	// Подкоманда `seed` выполняет заполнение независимо от этого параметра
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Wallets - количество создаваемых кошельков, если не задан Fixture (по умолчанию: 10)
+	Wallets int `yaml:"wallets" mapstructure:"wallets"`
+	// Balance - начальный баланс каждого кошелька в копейках (по умолчанию: 10000)
+	Balance int64 `yaml:"balance" mapstructure:"balance"`
+	// Seed - строка, из которой детерминированно выводятся адреса кошельков; пусто - случайные адреса
+	Seed string `yaml:"seed" mapstructure:"seed"`
+	// Fixture - путь к YAML- или JSON-файлу с кошельками и историческими транзакциями; заменяет Wallets и Balance
+	Fixture string `yaml:"fixture" mapstructure:"fixture"`
+}
+
+// PaymentsConfig содержит настройки приёма уведомлений платёжного провайдера о пополнениях и выводах
+type PaymentsConfig struct {
+	// CallbackSecret - секрет подписи уведомлений провайдера (схема X-Webhook-Signature); пусто - уведомления отклоняются
+	CallbackSecret string `yaml:"callback_secret" mapstructure:"callback_secret"`
+	// CallbackTolerance - допустимое расхождение времени подписи уведомления с текущим (по умолчанию: 5m)
+	CallbackTolerance time.Duration `yaml:"callback_tolerance" mapstructure:"callback_tolerance"`
+	// MaxCallbackBytes - максимальный размер тела уведомления (по умолчанию: 16 КиБ)
+	MaxCallbackBytes int64 `yaml:"max_callback_bytes" mapstructure:"max_callback_bytes"`
 }
 
 // MustLoad загружает конфигурацию из YAML-файла и передает ее в структуру Config
 // # Функция принимает логгер `zap.Logger` для записи ошибок при загрузке конфигурации
-// # Если файл конфигурации отсутствует или содержит ошибки, то программа завершается с фатальной ошибкой
+// # Если файл конфигурации отсутствует, содержит ошибки или не проходит валидацию,
+// # то программа завершается с фатальной ошибкой
 
 // Параметры:
 //
@@ -47,22 +285,127 @@ type DatabaseConfig struct {
 //
 //	-*Config: указатель на загруженную конфигурацию.
 func MustLoad(zLog *zap.Logger) *Config {
-	viper.SetConfigName("config")          // Имя файла-конфигурации
-	viper.SetConfigType("yaml")            // Формат файла-конфигурации
-	viper.AddConfigPath("internal/config") // Пусть к файлу-конфигурации
+	cfg, err := Load(DefaultPath)
+	if err != nil {
+		zLog.Fatal("Error loading config", zap.Error(err))
+	}
+
+	// Проверка конфигурации до запуска сервера
+	if err = cfg.Validate(); err != nil {
+		zLog.Fatal("Invalid config", zap.Error(err))
+	}
+
+	zLog.Info("Loaded config") // Логирование успешной загрузки конфигурации
+
+	return cfg
+}
+
+// Load читает YAML-файл конфигурации по указанному пути без валидации
+//
+// Параметры:
+//   - path (string): путь к файлу-конфигурации
+//
+// Возвращает:
+//   - *Config: указатель на загруженную конфигурацию
+//   - error: ошибку чтения или декодирования файла
+func Load(path string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(path) // Путь к файлу-конфигурации
+	v.SetConfigType("yaml")
+	setDefaults(v)
 
 	// Чтение конфигурации
-	if err := viper.ReadInConfig(); err != nil {
-		zLog.Fatal("Error reading config file: ", zap.Error(err))
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
 	}
 
 	var cfg Config
 	// Декодирование YAML в структуру Config
-	if err := viper.Unmarshal(&cfg); err != nil {
-		zLog.Fatal("Unable to unmarshal config file", zap.Error(err))
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config file: %w", err)
 	}
 
-	zLog.Info("Loaded config") // Логирование успешной загрузки конфигурации
+	return &cfg, nil
+}
+
+// setDefaults задаёт значения по умолчанию для параметров, отсутствующих в файле-конфигурации
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.read_timeout", 10*time.Second)
+	v.SetDefault("server.read_header_timeout", 5*time.Second)
+	v.SetDefault("server.write_timeout", 15*time.Second)
+	v.SetDefault("server.idle_timeout", 60*time.Second)
+	v.SetDefault("server.drain_delay", 0)
+	v.SetDefault("server.shutdown_timeout", 20*time.Second)
+	v.SetDefault("server.max_body_bytes", 1<<20)
+	v.SetDefault("server.trusted_proxies", []string{})
+
+	v.SetDefault("grpc.enabled", false)
+	v.SetDefault("grpc.address", "localhost:9090")
+
+	v.SetDefault("auth.enabled", true)
+	v.SetDefault("auth.bootstrap_admin_key", "")
+	v.SetDefault("auth.jwt.enabled", false)
+	v.SetDefault("auth.jwt.roles_claim", "roles")
+
+	v.SetDefault("ratelimit.enabled", true)
+	v.SetDefault("ratelimit.store", "memory")
+	v.SetDefault("ratelimit.ip.rate", 50)
+	v.SetDefault("ratelimit.ip.burst", 100)
+	v.SetDefault("ratelimit.client.rate", 20)
+	v.SetDefault("ratelimit.client.burst", 40)
+	v.SetDefault("ratelimit.sender.rate", 2)
+	v.SetDefault("ratelimit.sender.burst", 5)
+	v.SetDefault("ratelimit.cleanup_interval", time.Minute)
+	v.SetDefault("ratelimit.idle_ttl", 10*time.Minute)
+
+	v.SetDefault("stream.heartbeat_interval", 15*time.Second)
+	v.SetDefault("stream.buffer_size", 256)
+	v.SetDefault("stream.replay_limit", 1000)
+
+	v.SetDefault("webhooks.enabled", true)
+	v.SetDefault("webhooks.poll_interval", time.Second)
+	v.SetDefault("webhooks.batch_size", 50)
+	v.SetDefault("webhooks.timeout", 10*time.Second)
+	v.SetDefault("webhooks.max_attempts", 10)
+	v.SetDefault("webhooks.retry_backoff", 10*time.Second)
+	v.SetDefault("webhooks.retry_max_backoff", time.Hour)
+
+	v.SetDefault("openapi.validate_requests", true)
+	v.SetDefault("openapi.validate_responses", false)
+
+	v.SetDefault("api.v1_deprecated_at", "2026-10-19")
+	v.SetDefault("api.v1_sunset", "")
+
+	v.SetDefault("seed.enabled", true)
+	v.SetDefault("seed.wallets", 10)
+	v.SetDefault("seed.balance", 10000)
+	v.SetDefault("seed.seed", "")
+	v.SetDefault("seed.fixture", "")
+
+	v.SetDefault("payments.callback_secret", "")
+	v.SetDefault("payments.callback_tolerance", 5*time.Minute)
+	v.SetDefault("payments.max_callback_bytes", 16<<10)
+
+	v.SetDefault("log.level", "")
+	v.SetDefault("log.sampling.enabled", false)
+	v.SetDefault("log.sampling.initial", 100)
+	v.SetDefault("log.sampling.thereafter", 100)
+
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.exporter", "otlp")
+	v.SetDefault("tracing.endpoint", "localhost:4318")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.file_path", "traces.json")
+	v.SetDefault("tracing.service_name", "wallet-api")
+	v.SetDefault("tracing.sample_ratio", 1.0)
 
-	return &cfg
+	v.SetDefault("database.max_open_conns", 20)
+	v.SetDefault("database.max_idle_conns", 10)
+	v.SetDefault("database.conn_max_lifetime", 30*time.Minute)
+	v.SetDefault("database.conn_max_idle_time", 5*time.Minute)
+	v.SetDefault("database.statement_timeout", 5*time.Second)
+	v.SetDefault("database.connect_timeout", 5*time.Second)
+	v.SetDefault("database.connect_retries", 10)
+	v.SetDefault("database.retry_backoff", 500*time.Millisecond)
+	v.SetDefault("database.retry_max_backoff", 10*time.Second)
 }
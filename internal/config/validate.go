@@ -0,0 +1,411 @@
+// Package config отвечает за загрузку конфигурации приложения
+// с использованием библиотеки Viper
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sslModes - допустимые значения параметра sslmode для PostgreSQL
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// maxSeedWallets - максимальное количество кошельков, создаваемых начальным заполнением
+const maxSeedWallets = 100000
+
+// minCallbackSecret - минимальная длина секрета подписи уведомлений платёжного провайдера
+const minCallbackSecret = 16
+
+// logLevels - допустимые уровни логирования
+var logLevels = []string{"", "debug", "info", "warn", "error", "dpanic", "panic", "fatal"}
+
+// Validate проверяет всю конфигурацию и возвращает все найденные проблемы одной ошибкой
+//
+// Возвращает:
+//   - error: объединённая через errors.Join ошибка со списком проблем или nil, если конфигурация корректна
+//
+// Логика работы:
+//  1. Проверка настроек HTTP и gRPC серверов
+//  2. Проверка параметров подключения к базе данных
+//  3. Проверка настроек трассировки, логирования, аутентификации, ограничения частоты запросов, потоковых API, вебхуков, версий API, начального заполнения и платежей
+//  4. Объединение всех найденных ошибок, чтобы сообщить о них за один запуск
+func (c *Config) Validate() error {
+	var errs []error
+	errs = append(errs, c.Server.validate()...)
+	errs = append(errs, c.GRPC.validate(c.Server.Address)...)
+	errs = append(errs, c.Database.validate()...)
+	errs = append(errs, c.Tracing.validate()...)
+	errs = append(errs, c.Log.validate()...)
+	errs = append(errs, c.Auth.validate()...)
+	errs = append(errs, c.RateLimit.validate()...)
+	errs = append(errs, c.Stream.validate()...)
+	errs = append(errs, c.Webhooks.validate()...)
+	errs = append(errs, c.API.validate()...)
+	errs = append(errs, c.Seed.validate()...)
+	errs = append(errs, c.Payments.validate()...)
+	return errors.Join(errs...)
+}
+
+// validate проверяет настройки HTTP сервера
+func (s *ServerConfig) validate() []error {
+	var errs []error
+	if err := validateAddress(s.Address); err != nil {
+		errs = append(errs, fmt.Errorf("server.address: %w", err))
+	}
+	errs = appendNonNegative(errs, "server.read_timeout", s.ReadTimeout)
+	errs = appendNonNegative(errs, "server.read_header_timeout", s.ReadHeaderTimeout)
+	errs = appendNonNegative(errs, "server.write_timeout", s.WriteTimeout)
+	errs = appendNonNegative(errs, "server.idle_timeout", s.IdleTimeout)
+	errs = appendNonNegative(errs, "server.drain_delay", s.DrainDelay)
+	if s.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("server.shutdown_timeout: %s must be positive", s.ShutdownTimeout))
+	}
+	if s.MaxBodyBytes <= 0 {
+		errs = append(errs, fmt.Errorf("server.max_body_bytes: %d must be positive", s.MaxBodyBytes))
+	}
+	for _, proxy := range s.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("server.trusted_proxies: %q is not an IP address or CIDR network", proxy))
+		}
+	}
+	return errs
+}
+
+// validate проверяет настройки gRPC сервера; его адрес не должен совпадать с адресом HTTP сервера
+func (g *GRPCConfig) validate(httpAddress string) []error {
+	if !g.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if err := validateAddress(g.Address); err != nil {
+		errs = append(errs, fmt.Errorf("grpc.address: %w", err))
+	}
+	if g.Address == httpAddress {
+		errs = append(errs, fmt.Errorf("grpc.address: %q is already used by server.address", g.Address))
+	}
+	return errs
+}
+
+// validate проверяет параметры подключения к базе данных
+func (d *DatabaseConfig) validate() []error {
+	var errs []error
+	if d.Host == "" {
+		errs = append(errs, errors.New("database.host: must not be empty"))
+	}
+	if err := validatePort(d.Port); err != nil {
+		errs = append(errs, fmt.Errorf("database.port: %w", err))
+	}
+	if d.User == "" {
+		errs = append(errs, errors.New("database.user: must not be empty"))
+	}
+	if d.DBName == "" {
+		errs = append(errs, errors.New("database.dbname: must not be empty"))
+	}
+	if !slices.Contains(sslModes, d.SSlMode) {
+		errs = append(errs, fmt.Errorf("database.sslmode: %q is not one of %v", d.SSlMode, sslModes))
+	}
+
+	// Параметры пула соединений
+	if d.MaxOpenConns < 0 {
+		errs = append(errs, fmt.Errorf("database.max_open_conns: %d must not be negative", d.MaxOpenConns))
+	}
+	if d.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("database.max_idle_conns: %d must not be negative", d.MaxIdleConns))
+	}
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("database.max_idle_conns: %d exceeds max_open_conns %d", d.MaxIdleConns, d.MaxOpenConns))
+	}
+	errs = appendNonNegative(errs, "database.conn_max_lifetime", d.ConnMaxLifetime)
+	errs = appendNonNegative(errs, "database.conn_max_idle_time", d.ConnMaxIdleTime)
+
+	// Таймауты
+	errs = appendNonNegative(errs, "database.statement_timeout", d.StatementTimeout)
+	if d.StatementTimeout > 0 && d.StatementTimeout < time.Millisecond {
+		errs = append(errs, fmt.Errorf("database.statement_timeout: %s is below 1ms", d.StatementTimeout))
+	}
+	if d.ConnectTimeout < time.Second {
+		errs = append(errs, fmt.Errorf("database.connect_timeout: %s must be at least 1s", d.ConnectTimeout))
+	}
+
+	// Повторные попытки подключения
+	if d.ConnectRetries < 0 {
+		errs = append(errs, fmt.Errorf("database.connect_retries: %d must not be negative", d.ConnectRetries))
+	}
+	if d.ConnectRetries > 0 && d.RetryBackoff <= 0 {
+		errs = append(errs, fmt.Errorf("database.retry_backoff: %s must be positive when connect_retries is set", d.RetryBackoff))
+	}
+	if d.RetryMaxBackoff < d.RetryBackoff {
+		errs = append(errs, fmt.Errorf("database.retry_max_backoff: %s is less than retry_backoff %s", d.RetryMaxBackoff, d.RetryBackoff))
+	}
+	return errs
+}
+
+// validate проверяет настройки трассировки; при выключенной трассировке проверяется только sample_ratio
+func (t *TracingConfig) validate() []error {
+	var errs []error
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio: %v is out of range 0-1", t.SampleRatio))
+	}
+	if !t.Enabled {
+		return errs
+	}
+
+	if t.ServiceName == "" {
+		errs = append(errs, errors.New("tracing.service_name: must not be empty"))
+	}
+	switch t.Exporter {
+	case "otlp":
+		if t.Endpoint == "" {
+			errs = append(errs, errors.New("tracing.endpoint: must not be empty for otlp exporter"))
+		}
+	case "file":
+		if t.FilePath == "" {
+			errs = append(errs, errors.New("tracing.file_path: must not be empty for file exporter"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter: %q is not one of [otlp file]", t.Exporter))
+	}
+	return errs
+}
+
+// validate проверяет настройки логирования
+func (l *LogConfig) validate() []error {
+	var errs []error
+	if !slices.Contains(logLevels, l.Level) {
+		errs = append(errs, fmt.Errorf("log.level: %q is not one of %v", l.Level, logLevels[1:]))
+	}
+	if l.Sampling.Enabled {
+		if l.Sampling.Initial <= 0 {
+			errs = append(errs, fmt.Errorf("log.sampling.initial: %d must be positive", l.Sampling.Initial))
+		}
+		if l.Sampling.Thereafter < 0 {
+			errs = append(errs, fmt.Errorf("log.sampling.thereafter: %d must not be negative", l.Sampling.Thereafter))
+		}
+	}
+	return errs
+}
+
+// minBootstrapKeyLength - минимальная длина административного ключа из конфигурации
+const minBootstrapKeyLength = 16
+
+// bootstrapKeyPlaceholder - значение-заглушка из прежних версий config.yaml; ключ с ним известен всем
+const bootstrapKeyPlaceholder = "change-me-bootstrap-admin-key"
+
+// validate проверяет настройки аутентификации
+func (a *AuthConfig) validate() []error {
+	var errs []error
+	if a.BootstrapAdminKey != "" && len(a.BootstrapAdminKey) < minBootstrapKeyLength {
+		errs = append(errs, fmt.Errorf("auth.bootstrap_admin_key: must be at least %d characters", minBootstrapKeyLength))
+	}
+	if a.BootstrapAdminKey == bootstrapKeyPlaceholder {
+		errs = append(errs, errors.New("auth.bootstrap_admin_key: placeholder value must be replaced"))
+	}
+	errs = append(errs, a.JWT.validate()...)
+	return errs
+}
+
+// jwtRoles - роли, которым можно сопоставить значения утверждения JWT
+var jwtRoles = []string{"viewer", "operator", "admin"}
+
+// minJWTSecretBytes - минимальная длина общего секрета HMAC (static_key не в формате PEM): 256 бит для HS256
+const minJWTSecretBytes = 32
+
+// validate проверяет настройки проверки JWT
+func (j *JWTConfig) validate() []error {
+	if !j.Enabled {
+		return nil
+	}
+
+	var errs []error
+	switch {
+	case j.JWKSFile == "" && j.StaticKey == "":
+		errs = append(errs, errors.New("auth.jwt: one of jwks_file or static_key must be set"))
+	case j.JWKSFile != "" && j.StaticKey != "":
+		errs = append(errs, errors.New("auth.jwt: only one of jwks_file or static_key may be set"))
+	case j.JWKSFile != "":
+		if _, err := os.Stat(j.JWKSFile); err != nil {
+			errs = append(errs, fmt.Errorf("auth.jwt.jwks_file: %w", err))
+		}
+	case !strings.HasPrefix(strings.TrimSpace(j.StaticKey), "-----BEGIN") && len(j.StaticKey) < minJWTSecretBytes:
+		errs = append(errs, fmt.Errorf("auth.jwt.static_key: HMAC secret must be at least %d bytes", minJWTSecretBytes))
+	}
+	if j.RolesClaim == "" {
+		errs = append(errs, errors.New("auth.jwt.roles_claim: must not be empty"))
+	}
+	for claim, role := range j.RoleMapping {
+		if !slices.Contains(jwtRoles, role) {
+			errs = append(errs, fmt.Errorf("auth.jwt.role_mapping: %q maps to %q, which is not one of %v", claim, role, jwtRoles))
+		}
+	}
+	return errs
+}
+
+// validate проверяет настройки ограничения частоты запросов
+func (r *RateLimitConfig) validate() []error {
+	if !r.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if r.Store != "memory" && r.Store != "postgres" {
+		errs = append(errs, fmt.Errorf("ratelimit.store: %q is not one of [memory postgres]", r.Store))
+	}
+	errs = append(errs, r.IP.validate("ratelimit.ip")...)
+	errs = append(errs, r.Client.validate("ratelimit.client")...)
+	errs = append(errs, r.Sender.validate("ratelimit.sender")...)
+	if r.CleanupInterval <= 0 {
+		errs = append(errs, fmt.Errorf("ratelimit.cleanup_interval: %s must be positive", r.CleanupInterval))
+	}
+	if r.IdleTTL <= 0 {
+		errs = append(errs, fmt.Errorf("ratelimit.idle_ttl: %s must be positive", r.IdleTTL))
+	}
+	return errs
+}
+
+// validate проверяет параметры корзины токенов
+func (l *LimitConfig) validate(field string) []error {
+	var errs []error
+	if l.Rate <= 0 {
+		errs = append(errs, fmt.Errorf("%s.rate: %v must be positive", field, l.Rate))
+	}
+	if l.Burst < 1 {
+		errs = append(errs, fmt.Errorf("%s.burst: %d must be at least 1", field, l.Burst))
+	}
+	return errs
+}
+
+// validate проверяет настройки потоковых API
+func (s *StreamConfig) validate() []error {
+	var errs []error
+	if s.HeartbeatInterval <= 0 {
+		errs = append(errs, fmt.Errorf("stream.heartbeat_interval: %s must be positive", s.HeartbeatInterval))
+	}
+	if s.BufferSize < 1 {
+		errs = append(errs, fmt.Errorf("stream.buffer_size: %d must be at least 1", s.BufferSize))
+	}
+	if s.ReplayLimit < 1 {
+		errs = append(errs, fmt.Errorf("stream.replay_limit: %d must be at least 1", s.ReplayLimit))
+	}
+	return errs
+}
+
+// validate проверяет настройки доставки вебхуков
+func (w *WebhooksConfig) validate() []error {
+	if !w.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if w.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("webhooks.poll_interval: %s must be positive", w.PollInterval))
+	}
+	if w.BatchSize < 1 {
+		errs = append(errs, fmt.Errorf("webhooks.batch_size: %d must be at least 1", w.BatchSize))
+	}
+	if w.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("webhooks.timeout: %s must be positive", w.Timeout))
+	}
+	if w.MaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("webhooks.max_attempts: %d must be at least 1", w.MaxAttempts))
+	}
+	if w.RetryBackoff <= 0 {
+		errs = append(errs, fmt.Errorf("webhooks.retry_backoff: %s must be positive", w.RetryBackoff))
+	}
+	if w.RetryMaxBackoff < w.RetryBackoff {
+		errs = append(errs, fmt.Errorf("webhooks.retry_max_backoff: %s is less than retry_backoff %s", w.RetryMaxBackoff, w.RetryBackoff))
+	}
+	return errs
+}
+
+// validate проверяет даты устаревания /api/v1; дата отключения не может предшествовать дате объявления
+func (a *APIConfig) validate() []error {
+	var errs []error
+	deprecatedAt, err := time.Parse(time.DateOnly, a.V1DeprecatedAt)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("api.v1_deprecated_at: %q is not a YYYY-MM-DD date", a.V1DeprecatedAt))
+	}
+	if a.V1Sunset == "" {
+		return errs
+	}
+	sunset, err := time.Parse(time.DateOnly, a.V1Sunset)
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Errorf("api.v1_sunset: %q is not a YYYY-MM-DD date", a.V1Sunset))
+	case len(errs) == 0 && sunset.Before(deprecatedAt):
+		errs = append(errs, fmt.Errorf("api.v1_sunset: %s is before api.v1_deprecated_at %s", a.V1Sunset, a.V1DeprecatedAt))
+	}
+	return errs
+}
+
+// validate проверяет настройки начального заполнения
+func (s *SeedConfig) validate() []error {
+	var errs []error
+	if s.Wallets < 0 || s.Wallets > maxSeedWallets {
+		errs = append(errs, fmt.Errorf("seed.wallets: %d must be between 0 and %d", s.Wallets, maxSeedWallets))
+	}
+	if s.Balance < 0 {
+		errs = append(errs, fmt.Errorf("seed.balance: %d must not be negative", s.Balance))
+	}
+	if s.Fixture != "" {
+		if _, err := os.Stat(s.Fixture); err != nil {
+			errs = append(errs, fmt.Errorf("seed.fixture: %w", err))
+		}
+	}
+	return errs
+}
+
+// validate проверяет настройки уведомлений платёжного провайдера
+func (p *PaymentsConfig) validate() []error {
+	var errs []error
+	if p.CallbackSecret != "" && len(p.CallbackSecret) < minCallbackSecret {
+		errs = append(errs, fmt.Errorf("payments.callback_secret: must be at least %d characters", minCallbackSecret))
+	}
+	if p.CallbackTolerance <= 0 {
+		errs = append(errs, fmt.Errorf("payments.callback_tolerance: %s must be positive", p.CallbackTolerance))
+	}
+	if p.MaxCallbackBytes <= 0 {
+		errs = append(errs, fmt.Errorf("payments.max_callback_bytes: %d must be positive", p.MaxCallbackBytes))
+	}
+	return errs
+}
+
+// validateAddress проверяет, что адрес имеет формат "host:port" с корректным портом
+func validateAddress(address string) error {
+	if address == "" {
+		return errors.New("must not be empty")
+	}
+
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", address, err)
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return fmt.Errorf("invalid port %q in address %q", port, address)
+	}
+	return validatePort(p)
+}
+
+// appendNonNegative добавляет ошибку в errs, если длительность отрицательна
+func appendNonNegative(errs []error, field string, d time.Duration) []error {
+	if d < 0 {
+		return append(errs, fmt.Errorf("%s: %s must not be negative", field, d))
+	}
+	return errs
+}
+
+// validatePort проверяет, что порт находится в диапазоне 1-65535
+func validatePort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port %d is out of range 1-65535", port)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Package events содержит шину событий о зафиксированных изменениях для потоковых API
+package events
+
+import (
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"sync"
+)
+
+// Transfer - событие о зафиксированном переводе
+type Transfer struct {
+	Transaction models.Transaction // Запись транзакции
+	FromBalance int64              // Баланс отправителя после перевода
+	ToBalance   int64              // Баланс получателя после перевода
+}
+
+// Broker рассылает события о зафиксированных переводах подписчикам внутри процесса
+//
+// Публикация никогда не блокирует отправителя: если буфер подписчика заполнен,
+// подписка закрывается, а подписчик должен переподключиться и догнать пропущенное по базе данных
+type Broker struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// Subscription - подписка на события брокера
+type Subscription struct {
+	C <-chan Transfer // Канал событий; закрывается при отмене или переполнении подписки
+
+	ch      chan Transfer
+	broker  *Broker
+	dropped bool
+}
+
+// defaultBroker - брокер процесса, в который публикует сервисный слой
+var defaultBroker = NewBroker()
+
+// NewBroker создаёт брокер без подписчиков
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe создаёт подписку с буфером на buffer событий
+func (b *Broker) Subscribe(buffer int) *Subscription {
+	ch := make(chan Transfer, buffer)
+	s := &Subscription{C: ch, ch: ch, broker: b}
+
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Publish отправляет событие всем подписчикам; переполненные подписки закрываются
+func (b *Broker) Publish(e Transfer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subs {
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped = true
+			b.remove(s)
+		}
+	}
+}
+
+// remove удаляет подписку и закрывает её канал; вызывается под b.mu
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.ch)
+	}
+}
+
+// CloseAll закрывает все текущие подписки, чтобы потоковые обработчики завершились при остановке сервера
+func (b *Broker) CloseAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subs {
+		b.remove(s)
+	}
+}
+
+// Close отменяет подписку; повторный вызов безопасен
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	s.broker.remove(s)
+	s.broker.mu.Unlock()
+}
+
+// Dropped сообщает, была ли подписка закрыта из-за переполнения буфера
+func (s *Subscription) Dropped() bool {
+	s.broker.mu.RLock()
+	defer s.broker.mu.RUnlock()
+	return s.dropped
+}
+
+// Subscribe создаёт подписку на брокер процесса
+func Subscribe(buffer int) *Subscription {
+	return defaultBroker.Subscribe(buffer)
+}
+
+// Publish отправляет событие подписчикам брокера процесса
+func Publish(e Transfer) {
+	defaultBroker.Publish(e)
+}
+
+// CloseAll закрывает все подписки брокера процесса
+func CloseAll() {
+	defaultBroker.CloseAll()
+}
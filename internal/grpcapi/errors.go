@@ -0,0 +1,54 @@
+// Package grpcapi реализует gRPC API WalletService поверх сервисного слоя
+package grpcapi
+
+import (
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorStatusCodes сопоставляет коды ошибок сервисного слоя с кодами gRPC;
+// неизвестные коды соответствуют codes.Internal
+var errorStatusCodes = map[string]codes.Code{
+	"sender_not_found":       codes.NotFound,
+	"receiver_not_found":     codes.NotFound,
+	"wallet_not_found":       codes.NotFound,
+	"transaction_not_found":  codes.NotFound,
+	"not_enough_money":       codes.FailedPrecondition,
+	"public_key_already_set": codes.FailedPrecondition,
+	"wallet_frozen":          codes.FailedPrecondition,
+	"self_transfer":          codes.InvalidArgument,
+	"invalid_amount":         codes.InvalidArgument,
+	"invalid_public_key":     codes.InvalidArgument,
+	"signature_required":     codes.InvalidArgument,
+	"invalid_signature":      codes.InvalidArgument,
+	"invalid_nonce":          codes.InvalidArgument,
+	"system_wallet":          codes.InvalidArgument,
+	"memo_too_long":          codes.InvalidArgument,
+	"invalid_reference":      codes.InvalidArgument,
+	"invalid_metadata":       codes.InvalidArgument,
+	"invalid_status":         codes.InvalidArgument,
+	"reference_exists":       codes.AlreadyExists,
+	"forbidden":              codes.PermissionDenied,
+	"canceled":               codes.Canceled,
+	"timeout":                codes.DeadlineExceeded,
+}
+
+// toStatus преобразует ошибку сервисного слоя в статус gRPC
+//
+// Сообщение статуса совпадает с текстом ошибки, как в ответах HTTP API; ошибки, уже являющиеся
+// статусами gRPC, возвращаются без изменений
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code, ok := errorStatusCodes[services.ErrorCode(err)]
+	if !ok {
+		code = codes.Internal
+	}
+	return status.Error(code, err.Error())
+}
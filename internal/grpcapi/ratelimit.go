@@ -0,0 +1,75 @@
+// Package grpcapi реализует gRPC API WalletService поверх сервисного слоя
+package grpcapi
+
+import (
+	"context"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/ratelimit"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"math"
+	"strconv"
+)
+
+// retryAfterMetadata - ключ метаданных ответа с числом секунд до повторной попытки
+const retryAfterMetadata = "retry-after"
+
+// rateLimiter применяет к вызовам gRPC те же корзины токенов, что и промежуточные обработчики HTTP API
+// (middleware.IPRateLimit, ClientRateLimit и SenderRateLimit): ключи корзин совпадают, поэтому
+// переход с HTTP на gRPC не даёт дополнительных запросов
+type rateLimiter struct {
+	store ratelimit.Store // nil, если ограничение выключено
+	cfg   *config.RateLimitConfig
+}
+
+// ip ограничивает частоту вызовов с IP-адреса клиента; вызывается до аутентификации
+func (l *rateLimiter) ip(ctx context.Context) error {
+	return l.take(ctx, "ip:"+peerAddress(ctx), l.cfg.IP)
+}
+
+// client ограничивает частоту вызовов субъекта, сохранённого в контексте аутентификацией;
+// для анонимных вызовов - по IP-адресу
+func (l *rateLimiter) client(ctx context.Context) error {
+	key := "ip:" + peerAddress(ctx)
+	if p, ok := auth.FromContext(ctx); ok && p != auth.Anonymous {
+		key = p.Subject
+	}
+	return l.take(ctx, "client:"+key, l.cfg.Client)
+}
+
+// sender ограничивает частоту переводов с кошелька отправителя
+func (l *rateLimiter) sender(ctx context.Context, from string) error {
+	if from == "" {
+		return nil
+	}
+	return l.take(ctx, "sender:"+from, l.cfg.Sender)
+}
+
+// take списывает токен из корзины key и возвращает codes.ResourceExhausted с метаданными retry-after,
+// если токенов нет
+//
+// Ошибка хранилища не блокирует вызов: она записывается в лог, и вызов пропускается
+func (l *rateLimiter) take(ctx context.Context, key string, limit config.LimitConfig) error {
+	if l.store == nil {
+		return nil
+	}
+
+	res, err := l.store.Take(ctx, key, ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst})
+	if err != nil {
+		logger.FromContext(ctx).Error("Rate limit check failed", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	if res.Allowed {
+		return nil
+	}
+
+	retryAfter := max(int(math.Ceil(res.RetryAfter.Seconds())), 1)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterMetadata, strconv.Itoa(retryAfter)))
+	logger.AddFields(ctx, zap.String("rate_limited", key))
+	return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+}
@@ -0,0 +1,240 @@
+// Package grpcapi реализует gRPC API WalletService поверх сервисного слоя
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/ratelimit"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	walletv1 "github.com/normalniydada/test_task_infotecs/pkg/api/wallet/v1"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"net"
+	"strings"
+	"time"
+)
+
+// Ключи метаданных вызова
+const (
+	apiKeyMetadata        = "x-api-key"
+	authorizationMetadata = "authorization"
+	requestIDMetadata     = "x-request-id"
+)
+
+// bearerPrefix - префикс JWT в метаданных authorization
+const bearerPrefix = "Bearer "
+
+// methodRoles - минимальная роль для вызова метода; методы, отсутствующие в таблице, запрещены
+var methodRoles = map[string]string{
+	walletv1.WalletService_Send_FullMethodName:              auth.RoleOperator,
+	walletv1.WalletService_GetBalance_FullMethodName:        auth.RoleViewer,
+	walletv1.WalletService_ListTransactions_FullMethodName:  auth.RoleViewer,
+	walletv1.WalletService_GetTransaction_FullMethodName:    auth.RoleViewer,
+	walletv1.WalletService_WatchTransactions_FullMethodName: auth.RoleViewer,
+}
+
+// NewServer создаёт gRPC сервер с зарегистрированным WalletService
+//
+// Каждый вызов проходит через перехватчики, аналогичные промежуточным обработчикам HTTP API:
+//  1. Назначение идентификатора запроса (метаданные "x-request-id" или UUID) и логгера вызова
+//  2. Перехват паники с ответом codes.Internal
+//  3. Ограничение частоты вызовов по IP-адресу (до аутентификации)
+//  4. Аутентификация по метаданным "x-api-key" или "authorization: Bearer" и проверка роли для метода
+//  5. Ограничение частоты вызовов по субъекту; Send дополнительно ограничивается по кошельку отправителя
+//  6. Запись журнала вызовов с кодом ответа и длительностью
+//
+// Параметры:
+//   - db (*gorm.DB): подключение к базе данных
+//   - cfg (*config.Config): конфигурация приложения (аутентификация, потоковые API)
+//   - jwtVerifier (*auth.JWTVerifier): проверка JWT; nil, если JWT не принимаются
+//   - limiter (ratelimit.Store): корзины токенов, общие с HTTP API; nil, если ограничение выключено
+//   - zLog (*zap.Logger): логгер приложения
+func NewServer(db *gorm.DB, cfg *config.Config, jwtVerifier *auth.JWTVerifier, limiter ratelimit.Store, zLog *zap.Logger) *grpc.Server {
+	a := &authenticator{db: db, cfg: &cfg.Auth, jwtVerifier: jwtVerifier}
+	l := &rateLimiter{store: limiter, cfg: &cfg.RateLimit}
+
+	srv := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+			ctx, done := beginCall(ctx, zLog, info.FullMethod)
+			defer func() { done(recoverPanic(ctx, recover(), &err)) }()
+
+			if err = l.ip(ctx); err != nil {
+				return nil, err
+			}
+			if ctx, err = a.authenticate(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			if err = l.client(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+			ctx, done := beginCall(ss.Context(), zLog, info.FullMethod)
+			defer func() { done(recoverPanic(ctx, recover(), &err)) }()
+
+			if err = l.ip(ctx); err != nil {
+				return err
+			}
+			if ctx, err = a.authenticate(ctx, info.FullMethod); err != nil {
+				return err
+			}
+			if err = l.client(ctx); err != nil {
+				return err
+			}
+			return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		}),
+	)
+	walletv1.RegisterWalletServiceServer(srv, &walletService{db: db, stream: &cfg.Stream, limiter: l})
+	return srv
+}
+
+// serverStream подменяет контекст потока контекстом с логгером и субъектом вызова
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст вызова
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// beginCall сохраняет в контексте идентификатор запроса и логгер вызова
+//
+// Возвращает функцию, которая пишет запись журнала вызовов после его завершения
+func beginCall(ctx context.Context, zLog *zap.Logger, method string) (context.Context, func(error)) {
+	start := time.Now()
+
+	id := firstMetadata(ctx, requestIDMetadata)
+	if id == "" || len(id) > 128 {
+		id = uuid.NewString()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+
+	ctx = logger.ToContext(ctx, zLog.With(
+		zap.String("request_id", id),
+		zap.String("grpc_method", method),
+		zap.String("client_ip", peerAddress(ctx)),
+	))
+	ctx = audit.WithMeta(ctx, audit.Meta{RequestID: id, SourceIP: peerAddress(ctx)})
+
+	return ctx, func(err error) {
+		code := status.Code(err)
+		fields := []zap.Field{zap.String("code", code.String()), zap.Duration("latency", time.Since(start))}
+		zLog := logger.FromContext(ctx)
+		switch code {
+		case codes.OK, codes.Canceled:
+			zLog.Info("Call completed", fields...)
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			zLog.Error("Call completed", append(fields, zap.Error(err))...)
+		default:
+			zLog.Warn("Call completed", append(fields, zap.Error(err))...)
+		}
+	}
+}
+
+// recoverPanic пишет перехваченную панику в логгер вызова и заменяет ошибку на codes.Internal
+func recoverPanic(ctx context.Context, recovered any, err *error) error {
+	if recovered != nil {
+		logger.FromContext(ctx).Error("Panic recovered", zap.Any("panic", recovered), zap.Stack("stack"))
+		*err = status.Error(codes.Internal, "internal server error")
+	}
+	return *err
+}
+
+// authenticator определяет субъекта вызова так же, как middleware.Authenticate для HTTP API
+type authenticator struct {
+	db          *gorm.DB
+	cfg         *config.AuthConfig
+	jwtVerifier *auth.JWTVerifier
+}
+
+// authenticate сохраняет субъекта вызова в контексте и проверяет его роль для метода
+//
+// Возможные ошибки:
+//   - codes.Unauthenticated: учётные данные не переданы, ключ не существует или отозван, токен некорректен
+//   - codes.PermissionDenied: у субъекта нет роли, требуемой методом
+//   - codes.Internal: не удалось проверить ключ
+func (a *authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	p, err := a.principal(ctx)
+	if err != nil {
+		return ctx, err
+	}
+
+	ctx = auth.WithPrincipal(ctx, p)
+	meta := audit.MetaFromContext(ctx)
+	meta.Actor = p.Subject
+	ctx = audit.WithMeta(ctx, meta)
+	logger.AddFields(ctx, zap.String("principal", p.Subject), zap.String("role", p.Role))
+
+	role, ok := methodRoles[method]
+	if !ok || !p.HasRole(role) {
+		return ctx, status.Errorf(codes.PermissionDenied, "%s role required", role)
+	}
+	return ctx, nil
+}
+
+// principal определяет субъекта вызова по JWT или ключу доступа
+func (a *authenticator) principal(ctx context.Context) (*auth.Principal, error) {
+	if !a.cfg.Enabled {
+		return auth.Anonymous, nil
+	}
+
+	if header := firstMetadata(ctx, authorizationMetadata); a.jwtVerifier != nil && strings.HasPrefix(header, bearerPrefix) {
+		p, err := a.jwtVerifier.Verify(strings.TrimPrefix(header, bearerPrefix))
+		if err != nil {
+			logger.FromContext(ctx).Info("JWT rejected", zap.Error(err))
+			return nil, status.Error(codes.Unauthenticated, auth.ErrInvalidToken.Error())
+		}
+		return p, nil
+	}
+
+	raw := firstMetadata(ctx, apiKeyMetadata)
+	if raw == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing credentials")
+	}
+
+	key, err := services.AuthenticateAPIKey(ctx, a.db, raw)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		logger.FromContext(ctx).Error("API key lookup failed", zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return auth.NewAPIKeyPrincipal(key), nil
+}
+
+// firstMetadata возвращает первое значение ключа из входящих метаданных вызова
+func firstMetadata(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// peerAddress возвращает IP-адрес клиента вызова
+func peerAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
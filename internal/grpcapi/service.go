@@ -0,0 +1,203 @@
+// Package grpcapi реализует gRPC API WalletService поверх сервисного слоя
+package grpcapi
+
+import (
+	"context"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/events"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	walletv1 "github.com/normalniydada/test_task_infotecs/pkg/api/wallet/v1"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+	"slices"
+)
+
+// maxListCount - максимальное число транзакций в ответе ListTransactions
+const maxListCount = 1000
+
+// walletService реализует walletv1.WalletServiceServer через сервисный слой, общий с HTTP API
+type walletService struct {
+	walletv1.UnimplementedWalletServiceServer
+
+	db      *gorm.DB
+	stream  *config.StreamConfig
+	limiter *rateLimiter
+}
+
+// Send переводит средства между кошельками
+//
+// Списывать средства можно только с кошельков, привязанных к ключу доступа субъекта вызова;
+// частота переводов с кошелька ограничивается так же, как для POST /api/send
+func (s *walletService) Send(ctx context.Context, req *walletv1.SendRequest) (*walletv1.SendResponse, error) {
+	logger.AddFields(ctx, zap.String("from", req.GetFrom()), zap.String("to", req.GetTo()))
+
+	// Лимит списывается только после проверки прав, иначе любой клиент мог бы исчерпать лимит чужого кошелька
+	if !principal(ctx).CanDebit(req.GetFrom()) {
+		return nil, toStatus(services.ErrForbidden)
+	}
+
+	if err := s.limiter.sender(ctx, req.GetFrom()); err != nil {
+		return nil, err
+	}
+
+	var sig *services.Signature
+	if req.GetSignature() != "" {
+		sig = &services.Signature{Nonce: req.GetNonce(), Value: req.GetSignature()}
+	}
+
+	details := services.TransferDetails{Memo: req.GetMemo(), Reference: req.GetReference(), Metadata: req.GetMetadata()}
+	transaction, err := services.TransferMoney(ctx, s.db, req.GetFrom(), req.GetTo(), req.GetAmount(), details, sig)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &walletv1.SendResponse{Transaction: newTransaction(transaction)}, nil
+}
+
+// GetBalance возвращает баланс кошелька, доступного субъекту вызова
+func (s *walletService) GetBalance(ctx context.Context, req *walletv1.GetBalanceRequest) (*walletv1.GetBalanceResponse, error) {
+	if !principal(ctx).CanRead(req.GetAddress()) {
+		return nil, toStatus(services.ErrForbidden)
+	}
+
+	balance, err := services.GetWalletBalance(ctx, s.db, req.GetAddress())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &walletv1.GetBalanceResponse{Address: req.GetAddress(), Balance: balance}, nil
+}
+
+// ListTransactions возвращает последние транзакции кошельков, доступных субъекту вызова,
+// при заданном reference - только с этим внешним идентификатором, при заданном status - только
+// в этом состоянии; без status отклонённые попытки не возвращаются
+func (s *walletService) ListTransactions(ctx context.Context, req *walletv1.ListTransactionsRequest) (*walletv1.ListTransactionsResponse, error) {
+	if req.GetCount() <= 0 || req.GetCount() > maxListCount {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be between 1 and %d", maxListCount)
+	}
+
+	transactions, err := services.GetLastNTransactions(ctx, s.db, int(req.GetCount()), principal(ctx).ReadableWallets(), req.GetReference(), req.GetStatus())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &walletv1.ListTransactionsResponse{Transactions: make([]*walletv1.Transaction, len(transactions))}
+	for i := range transactions {
+		resp.Transactions[i] = newTransaction(&transactions[i])
+	}
+	return resp, nil
+}
+
+// GetTransaction возвращает транзакцию, в которой участвует доступный субъекту вызова кошелёк
+//
+// Чужие транзакции не отличаются от несуществующих
+func (s *walletService) GetTransaction(ctx context.Context, req *walletv1.GetTransactionRequest) (*walletv1.Transaction, error) {
+	transaction, err := services.GetTransaction(ctx, s.db, uint(req.GetId()))
+	if err == nil && !canReadTransaction(principal(ctx), transaction) {
+		err = services.ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return newTransaction(transaction), nil
+}
+
+// WatchTransactions отправляет транзакции по мере их фиксации
+//
+// Если задан after_id, сначала отправляются пропущенные транзакции (не более stream.replay_limit).
+// Если клиент не успевает читать, вызов завершается с codes.ResourceExhausted, и клиент
+// продолжает с after_id последней полученной транзакции
+func (s *walletService) WatchTransactions(req *walletv1.WatchTransactionsRequest, stream walletv1.WalletService_WatchTransactionsServer) error {
+	ctx := stream.Context()
+	p := principal(ctx)
+
+	wallets := p.ReadableWallets()
+	if len(req.GetWallets()) > 0 {
+		for _, address := range req.GetWallets() {
+			if !p.CanRead(address) {
+				return toStatus(services.ErrForbidden)
+			}
+		}
+		wallets = req.GetWallets()
+	}
+
+	// Подписка оформляется до догоняющей выборки, чтобы не потерять транзакции между ними
+	sub := events.Subscribe(s.stream.BufferSize)
+	defer sub.Close()
+
+	sent := make(map[uint]struct{})
+	if req.GetAfterId() > 0 {
+		replay, err := services.GetTransactionsAfter(ctx, s.db, uint(req.GetAfterId()), wallets, s.stream.ReplayLimit)
+		if err != nil {
+			return toStatus(err)
+		}
+		for i := range replay {
+			if err = stream.Send(newTransaction(&replay[i])); err != nil {
+				return err
+			}
+			sent[replay[i].ID] = struct{}{}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return toStatus(ctx.Err())
+		case e, ok := <-sub.C:
+			if !ok {
+				if sub.Dropped() {
+					return status.Error(codes.ResourceExhausted, "client is too slow, resume with after_id")
+				}
+				return status.Error(codes.Unavailable, "server shutting down")
+			}
+			t := e.Transaction
+			if _, dup := sent[t.ID]; dup {
+				continue
+			}
+			if wallets != nil && !slices.Contains(wallets, t.From) && !slices.Contains(wallets, t.To) {
+				continue
+			}
+			if err := stream.Send(newTransaction(&t)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// principal возвращает субъекта вызова, сохранённого перехватчиком аутентификации
+func principal(ctx context.Context) *auth.Principal {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		// Перехватчик не пропускает вызовы без субъекта; пустой субъект не имеет прав
+		return &auth.Principal{}
+	}
+	return p
+}
+
+// canReadTransaction проверяет, доступен ли субъекту кошелёк отправителя или получателя
+func canReadTransaction(p *auth.Principal, t *models.Transaction) bool {
+	return p.CanRead(t.From) || p.CanRead(t.To)
+}
+
+// newTransaction преобразует модель транзакции в сообщение protobuf
+func newTransaction(t *models.Transaction) *walletv1.Transaction {
+	return &walletv1.Transaction{
+		Id:         uint64(t.ID),
+		From:       t.From,
+		To:         t.To,
+		Amount:     t.Amount,
+		CreatedAt:  timestamppb.New(t.CreatedAt),
+		Type:       t.Type,
+		ReasonCode: t.ReasonCode,
+		Note:       t.Note,
+		Status:     t.Status,
+		ErrorCode:  t.ErrorCode,
+		Memo:       t.Memo,
+		Reference:  t.Reference,
+		Metadata:   t.Metadata,
+	}
+}
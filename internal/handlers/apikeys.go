@@ -0,0 +1,141 @@
+// Package handlers содержит обработчики HTTP-запросов для управления ключами доступа к API
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+// CreateAPIKey создаёт ключ доступа к API.
+//
+// POST /api/admin/keys
+//
+// Тело запроса (JSON):
+//
+//	{
+//	  "name": "billing-service",
+//	  "admin": false,
+//	  "wallets": ["e240d825..."]
+//	}
+//
+// Ответ:
+//   - 201 Created: ключ, включая его значение в поле "key" (показывается один раз)
+//   - 400 Bad Request: если тело запроса некорректно или кошелёк не существует
+func CreateAPIKey(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CreateAPIKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		raw, key, err := services.CreateAPIKey(c.Request.Context(), db, req.Name, req.Admin, req.Wallets)
+		if err != nil {
+			respondError(c, apiKeyErrorStatus(err), err)
+			return
+		}
+
+		resp := dto.NewAPIKeyResponse(key)
+		resp.Key = raw
+		c.JSON(http.StatusCreated, resp)
+	}
+}
+
+// ListAPIKeys возвращает все ключи доступа, включая отозванные.
+//
+// GET /api/admin/keys
+//
+// Ответ:
+//   - 200 OK: JSON-массив ключей без их значений
+func ListAPIKeys(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys, err := services.ListAPIKeys(c.Request.Context(), db)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		resp := make([]dto.APIKeyResponse, len(keys))
+		for i := range keys {
+			resp[i] = dto.NewAPIKeyResponse(&keys[i])
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// SetAPIKeyWallets заменяет список кошельков, с которых владелец ключа может списывать средства.
+//
+// PUT /api/admin/keys/{id}/wallets
+//
+// Тело запроса (JSON):
+//
+//	{"wallets": ["e240d825...", "9a1f..."]}
+//
+// Ответ:
+//   - 200 OK: обновлённый ключ
+//   - 400 Bad Request: если тело запроса некорректно или кошелёк не существует
+//   - 404 Not Found: если ключ не найден или отозван
+func SetAPIKeyWallets(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			badRequest(c, "Invalid key id")
+			return
+		}
+
+		var req dto.SetAPIKeyWalletsRequest
+		if err = c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		key, err := services.SetAPIKeyWallets(c.Request.Context(), db, uint(id), req.Wallets)
+		if err != nil {
+			respondError(c, apiKeyErrorStatus(err), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewAPIKeyResponse(key))
+	}
+}
+
+// RevokeAPIKey отзывает ключ доступа.
+//
+// DELETE /api/admin/keys/{id}
+//
+// Ответ:
+//   - 200 OK: {"status": "revoked"}
+//   - 404 Not Found: если ключ не найден или уже отозван
+func RevokeAPIKey(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			badRequest(c, "Invalid key id")
+			return
+		}
+
+		if err = services.RevokeAPIKey(c.Request.Context(), db, uint(id)); err != nil {
+			respondError(c, apiKeyErrorStatus(err), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "revoked"})
+	}
+}
+
+// apiKeyErrorStatus возвращает HTTP-статус для ошибки операции с ключом доступа
+func apiKeyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrWalletNotFound):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
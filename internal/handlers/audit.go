@@ -0,0 +1,102 @@
+// Package handlers содержит обработчики HTTP-запросов для чтения журнала аудита
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Ограничения размера страницы журнала аудита
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// GetAuditLogs возвращает записи журнала аудита, начиная с самых новых.
+//
+// GET /api/admin/audit?actor=&action=&entity_type=&entity_id=&request_id=&since=&until=&before_id=&limit=
+//
+// Параметры запроса (все необязательные):
+//   - actor, action, entity_type, entity_id, request_id (string) — точное совпадение
+//   - since, until (RFC 3339) — интервал времени записи [since, until)
+//   - before_id (uint) — только записи с ID меньше указанного, для перехода к следующей странице
+//   - limit (int) — число записей, от 1 до 1000 (по умолчанию 100)
+//
+// Журнал доступен только для чтения: маршрутов изменения и удаления записей нет.
+//
+// Ответ:
+//   - 200 OK: JSON-массив записей
+//   - 400 Bad Request: если параметр запроса некорректен
+func GetAuditLogs(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := services.AuditFilter{
+			Actor:      c.Query("actor"),
+			Action:     c.Query("action"),
+			EntityType: c.Query("entity_type"),
+			EntityID:   c.Query("entity_id"),
+			RequestID:  c.Query("request_id"),
+			Limit:      defaultAuditLimit,
+		}
+
+		var err error
+		if filter.Since, err = parseTimeQuery(c, "since"); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		if filter.Until, err = parseTimeQuery(c, "until"); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		if v := c.Query("before_id"); v != "" {
+			if filter.BeforeID, err = strconv.ParseUint(v, 10, 64); err != nil {
+				badRequest(c, "Invalid before_id value")
+				return
+			}
+		}
+		if v := c.Query("limit"); v != "" {
+			if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 || filter.Limit > maxAuditLimit {
+				badRequest(c, "Invalid limit value")
+				return
+			}
+		}
+
+		logs, err := services.ListAuditLogs(c.Request.Context(), db, filter)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		resp := make([]dto.AuditLogResponse, len(logs))
+		for i := range logs {
+			resp[i] = dto.NewAuditLogResponse(&logs[i])
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// parseTimeQuery разбирает необязательный параметр запроса в формате RFC 3339
+func parseTimeQuery(c *gin.Context, name string) (time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, &queryError{name: name}
+	}
+	return t, nil
+}
+
+// queryError - ошибка разбора параметра запроса
+type queryError struct {
+	name string
+}
+
+func (e *queryError) Error() string {
+	return "Invalid " + e.name + " value"
+}
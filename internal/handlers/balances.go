@@ -0,0 +1,244 @@
+// Package handlers содержит обработчики WebSocket-подписки на изменения балансов
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/events"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+// maxBalanceSubscriptions - максимальное число кошельков в подписках одного соединения
+const maxBalanceSubscriptions = 100
+
+// maxBalanceMessageSize - максимальный размер сообщения клиента в байтах
+const maxBalanceMessageSize = 16 << 10
+
+// errTooManySubscriptions - ошибка: превышено число кошельков в подписках соединения
+var errTooManySubscriptions = errors.New("too many subscriptions")
+
+// upgrader переводит HTTP-соединение на протокол WebSocket; запросы с чужим Origin отклоняются
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// SubscribeBalances отправляет клиенту изменения балансов выбранных кошельков по WebSocket.
+//
+// GET /api/ws/balances
+//
+// Сообщения клиента (JSON):
+//
+//	{"action": "subscribe", "wallets": ["wallet1", "wallet2"]}
+//	{"action": "unsubscribe", "wallets": ["wallet2"]}
+//
+// Сообщения сервера (JSON):
+//   - {"type": "subscribed", "wallets": [...], "balances": {"wallet1": 100}} — подписка оформлена, текущие балансы
+//   - {"type": "unsubscribed", "wallets": [...]} — подписка отменена
+//   - {"type": "balance", "wallet": "wallet1", "balance": 66.7, "delta": -33.3, "transaction_id": 42} —
+//     перевод изменил баланс кошелька
+//   - {"type": "error", "error": "..."} — сообщение клиента не обработано, соединение остаётся открытым
+//
+// Подписаться можно только на кошельки, доступные субъекту запроса, не более 100 на соединение.
+// Раз в stream.heartbeat_interval сервер отправляет ping и закрывает соединение, если pong
+// не пришёл за два интервала. Если клиент не успевает читать сообщения и очередь
+// из stream.buffer_size событий переполняется, соединение закрывается с кодом 1013 (Try Again Later);
+// после переподключения клиент получает актуальные балансы при подписке.
+func SubscribeBalances(db *gorm.DB, cfg *config.StreamConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// Upgrader уже ответил клиенту кодом ошибки
+			return
+		}
+		defer conn.Close()
+
+		ctx := c.Request.Context()
+		log := logger.FromContext(ctx)
+
+		sub := events.Subscribe(cfg.BufferSize)
+		defer sub.Close()
+
+		// Чтение сообщений клиента в отдельной горутине; запись выполняется только в этой
+		requests := make(chan dto.BalanceSubscriptionRequest)
+		readErr := make(chan error, 1)
+		done := make(chan struct{})
+		defer close(done)
+		go readBalanceRequests(conn, cfg.HeartbeatInterval*2, requests, readErr, done)
+
+		write := func(msg dto.BalanceMessage) bool {
+			_ = conn.SetWriteDeadline(time.Now().Add(cfg.HeartbeatInterval))
+			if err := conn.WriteJSON(msg); err != nil {
+				log.Debug("WebSocket write failed", zap.Error(err))
+				return false
+			}
+			return true
+		}
+		closeWith := func(code int, reason string) {
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason),
+				time.Now().Add(time.Second))
+		}
+
+		heartbeat := time.NewTicker(cfg.HeartbeatInterval)
+		defer heartbeat.Stop()
+
+		subscribed := make(map[string]struct{})
+		for {
+			select {
+			case <-ctx.Done():
+				closeWith(websocket.CloseGoingAway, "server shutting down")
+				return
+			case err := <-readErr:
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					log.Debug("WebSocket read failed", zap.Error(err))
+				}
+				return
+			case req := <-requests:
+				if !write(handleBalanceRequest(ctx, db, p, subscribed, req)) {
+					return
+				}
+			case e, ok := <-sub.C:
+				if !ok {
+					if sub.Dropped() {
+						log.Warn("Slow WebSocket client disconnected", zap.Int("subscriptions", len(subscribed)))
+						closeWith(websocket.CloseTryAgainLater, "client is too slow")
+					} else {
+						closeWith(websocket.CloseGoingAway, "server shutting down")
+					}
+					return
+				}
+				for _, msg := range balanceMessages(e, subscribed) {
+					if !write(msg) {
+						return
+					}
+				}
+			case <-heartbeat.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(cfg.HeartbeatInterval)); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// readBalanceRequests читает сообщения клиента, пока соединение открыто и done не закрыт
+//
+// Каждое сообщение и каждый pong продлевают дедлайн чтения на timeout;
+// ошибка чтения (в том числе истечение дедлайна) передаётся в errc.
+// Сообщение, не являющееся корректным JSON, передаётся как запрос без действия
+func readBalanceRequests(conn *websocket.Conn, timeout time.Duration, requests chan<- dto.BalanceSubscriptionRequest, errc chan<- error, done <-chan struct{}) {
+	conn.SetReadLimit(maxBalanceMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(timeout))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			errc <- err
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(timeout))
+
+		var req dto.BalanceSubscriptionRequest
+		_ = json.Unmarshal(data, &req)
+		select {
+		case requests <- req:
+		case <-done:
+			return
+		}
+	}
+}
+
+// handleBalanceRequest обрабатывает сообщение клиента и возвращает ответ на него
+//
+// При подписке проверяется доступ субъекта к кошелькам и их существование,
+// а в ответ включаются текущие балансы; при ошибке подписки набор кошельков не меняется
+func handleBalanceRequest(ctx context.Context, db *gorm.DB, p *auth.Principal, subscribed map[string]struct{}, req dto.BalanceSubscriptionRequest) dto.BalanceMessage {
+	if len(req.Wallets) == 0 {
+		return balanceError(errors.New("wallets must not be empty"))
+	}
+	wallets := slices.Compact(slices.Sorted(slices.Values(req.Wallets)))
+
+	switch req.Action {
+	case dto.BalanceActionSubscribe:
+		balances := make(map[string]float64, len(wallets))
+		added := 0
+		for _, address := range wallets {
+			if !p.CanRead(address) {
+				return balanceError(services.ErrForbidden)
+			}
+			balance, err := services.GetWalletBalance(ctx, db, address)
+			if err != nil {
+				return balanceError(err)
+			}
+			balances[address] = convertMoneyToFloat(balance)
+			if _, ok := subscribed[address]; !ok {
+				added++
+			}
+		}
+		if len(subscribed)+added > maxBalanceSubscriptions {
+			return balanceError(errTooManySubscriptions)
+		}
+		for _, address := range wallets {
+			subscribed[address] = struct{}{}
+		}
+		logger.FromContext(ctx).Debug("Balance subscription updated", zap.Int("subscriptions", len(subscribed)))
+		return dto.BalanceMessage{Type: dto.BalanceMessageSubscribed, Wallets: wallets, Balances: balances}
+	case dto.BalanceActionUnsubscribe:
+		for _, address := range wallets {
+			delete(subscribed, address)
+		}
+		return dto.BalanceMessage{Type: dto.BalanceMessageUnsubscribed, Wallets: wallets}
+	default:
+		return balanceError(fmt.Errorf("unknown action %q", req.Action))
+	}
+}
+
+// balanceMessages возвращает сообщения об изменении балансов подписанных кошельков, затронутых переводом
+func balanceMessages(e events.Transfer, subscribed map[string]struct{}) []dto.BalanceMessage {
+	t := e.Transaction
+	var msgs []dto.BalanceMessage
+	if _, ok := subscribed[t.From]; ok {
+		msgs = append(msgs, newBalanceMessage(t.From, e.FromBalance, -t.Amount, t.ID))
+	}
+	if _, ok := subscribed[t.To]; ok {
+		msgs = append(msgs, newBalanceMessage(t.To, e.ToBalance, t.Amount, t.ID))
+	}
+	return msgs
+}
+
+// newBalanceMessage создаёт сообщение об изменении баланса кошелька
+func newBalanceMessage(address string, balance int64, delta int64, transactionID uint) dto.BalanceMessage {
+	value := convertMoneyToFloat(balance)
+	return dto.BalanceMessage{
+		Type:          dto.BalanceMessageBalance,
+		Wallet:        address,
+		Balance:       &value,
+		Delta:         convertMoneyToFloat(delta),
+		TransactionID: transactionID,
+	}
+}
+
+// balanceError создаёт сообщение об ошибке обработки сообщения клиента
+func balanceError(err error) dto.BalanceMessage {
+	return dto.BalanceMessage{Type: dto.BalanceMessageError, Error: err.Error()}
+}
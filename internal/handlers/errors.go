@@ -0,0 +1,29 @@
+// Package handlers содержит обработчики HTTP-запросов
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/apiversion"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"net/http"
+)
+
+// codeInvalidRequest - код ошибки разбора или проверки параметров запроса
+const codeInvalidRequest = "invalid_request"
+
+// respondError отправляет ошибку в формате версии API запроса
+//
+// Код ошибки берётся из сервисного слоя (services.ErrorCode); ошибки, неизвестные сервисному слою,
+// при статусе 4xx считаются ошибками разбора запроса и получают код "invalid_request"
+func respondError(c *gin.Context, status int, err error) {
+	code := services.ErrorCode(err)
+	if code == "internal" && status < http.StatusInternalServerError {
+		code = codeInvalidRequest
+	}
+	apiversion.Error(c, status, code, err.Error())
+}
+
+// badRequest отправляет 400 Bad Request с кодом "invalid_request"
+func badRequest(c *gin.Context, message string) {
+	apiversion.Error(c, http.StatusBadRequest, codeInvalidRequest, message)
+}
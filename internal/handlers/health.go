@@ -0,0 +1,40 @@
+// Package handlers содержит обработчики HTTP-запросов для проверки состояния приложения
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/health"
+	"net/http"
+)
+
+// Healthz сообщает, что процесс запущен и обрабатывает запросы
+//
+// GET /healthz
+//
+// Ответ:
+//   - 200 OK: {"status": "ok"}
+func Healthz() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+	}
+}
+
+// Readyz сообщает, готово ли приложение обслуживать запросы
+//
+// GET /readyz
+//
+// Ответ:
+//   - 200 OK: {"status": "ok", "checks": {...}} — если все зависимости доступны
+//   - 503 Service Unavailable: {"status": "fail", "checks": {...}} — если хотя бы одна проверка не пройдена
+//     или сервер находится в процессе остановки
+func Readyz(checker *health.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checker.Check(c.Request.Context())
+		if !report.Ready() {
+			c.JSON(http.StatusServiceUnavailable, report)
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
@@ -0,0 +1,270 @@
+// Package handlers содержит обработчики HTTP-запросов для пополнений и выводов средств
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/apiversion"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"github.com/normalniydada/test_task_infotecs/internal/webhooks"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// PaymentSignatureHeader - заголовок с подписью уведомления платёжного провайдера в формате webhooks.Sign
+const PaymentSignatureHeader = "X-Payment-Signature"
+
+// CreateDeposit создаёт пополнение кошелька, ожидающее подтверждения платёжного провайдера
+//
+// POST /api/deposits
+//
+// Тело запроса (JSON):
+//
+//	{"wallet": "...", "amount": 250, "external_id": "psp-7f3a91"}
+//
+// Средства зачисляются после уведомления провайдера (см. PaymentCallback). В API v2 сумма передаётся строкой.
+//
+// Ответ:
+//   - 201 Created: платёж в состоянии pending
+//   - 400 Bad Request: если сумма или внешний идентификатор некорректны
+//   - 403 Forbidden: если субъект запроса не владеет кошельком
+//   - 404 Not Found: если кошелёк не найден
+//   - 409 Conflict: если платёж с таким внешним идентификатором уже создан
+//   - 422 Unprocessable Entity: если кошелёк заморожен
+func CreateDeposit(db *gorm.DB) gin.HandlerFunc {
+	return createPayment(db, func(ctx context.Context, db *gorm.DB, address string, amount int64, externalID string, _ *services.Signature) (*models.Payment, error) {
+		return services.CreateDeposit(ctx, db, address, amount, externalID)
+	})
+}
+
+// CreateWithdrawal создаёт вывод средств с кошелька, ожидающий подтверждения платёжного провайдера
+//
+// POST /api/withdrawals
+//
+// Тело запроса то же, что и у CreateDeposit. Если у кошелька зарегистрирован ключ, вывод подписывается
+// как перевод: поля nonce и signature, подписывается сообщение services.WithdrawalMessage.
+// Средства списываются сразу и возвращаются, если провайдер отклонит вывод.
+//
+// Ответ:
+//   - 201 Created: платёж в состоянии pending
+//   - 400 Bad Request: если сумма или внешний идентификатор некорректны, подпись отсутствует или неверна
+//   - 403 Forbidden: если субъект запроса не владеет кошельком
+//   - 404 Not Found: если кошелёк не найден
+//   - 409 Conflict: если платёж с таким внешним идентификатором уже создан
+//   - 422 Unprocessable Entity: если недостаточно средств, кошелёк заморожен или nonce уже использован
+func CreateWithdrawal(db *gorm.DB) gin.HandlerFunc {
+	return createPayment(db, services.CreateWithdrawal)
+}
+
+// createPayment возвращает обработчик создания платежа функцией сервисного слоя create;
+// sig - подпись из тела запроса или nil, если подпись не передана
+func createPayment(db *gorm.DB, create func(ctx context.Context, db *gorm.DB, address string, amount int64, externalID string, sig *services.Signature) (*models.Payment, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := bindPaymentRequest(c)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+		if !p.CanDebit(req.Wallet) {
+			respondError(c, http.StatusForbidden, services.ErrForbidden)
+			return
+		}
+
+		var sig *services.Signature
+		if req.Signature != "" {
+			sig = &services.Signature{Nonce: req.Nonce, Value: req.Signature}
+		}
+
+		payment, err := create(c.Request.Context(), db, req.Wallet, int64(req.Amount), req.ExternalID, sig)
+		logger.AddFields(c.Request.Context(), zap.String("address", req.Wallet), zap.String("outcome", outcome(err)))
+		if err != nil {
+			status := paymentErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				logger.FromContext(c.Request.Context()).Error("Request failed", zap.String("route", c.FullPath()), zap.Error(err))
+			}
+			respondError(c, status, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, newPaymentResponse(c, payment))
+	}
+}
+
+// GetPayment возвращает пополнение или вывод средств по идентификатору
+//
+// GET /api/payments/{id}
+//
+// Платежи кошельков, недоступных субъекту запроса, не отличаются от несуществующих.
+//
+// Ответ:
+//   - 200 OK: платёж
+//   - 400 Bad Request: если идентификатор некорректен
+//   - 404 Not Found: если платёж не найден
+func GetPayment(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			badRequest(c, "Invalid payment id")
+			return
+		}
+
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+
+		payment, err := services.GetPayment(c.Request.Context(), db, uint(id))
+		if err == nil && !p.CanRead(payment.WalletAddress) {
+			err = services.ErrPaymentNotFound
+		}
+		if err != nil {
+			respondError(c, paymentErrorStatus(err), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, newPaymentResponse(c, payment))
+	}
+}
+
+// PaymentCallback принимает уведомление платёжного провайдера о результате пополнения или вывода
+//
+// POST /api/payments/callback
+//
+// Запрос не требует ключа доступа: тело подписывается секретом `payments.callback_secret` по схеме
+// вебхуков (webhooks.Sign), подпись передаётся в заголовке X-Payment-Signature. Тело не больше
+// `payments.max_callback_bytes`; маршрут подключается за ограничением частоты запросов по IP-адресу.
+//
+// Тело запроса (JSON):
+//
+//	{"external_id": "psp-7f3a91", "status": "completed"}
+//	{"external_id": "psp-7f3a91", "status": "failed", "failure_code": "card_declined"}
+//
+// Повторное уведомление с тем же результатом не изменяет платёж.
+//
+// Ответ:
+//   - 200 OK: платёж после изменения
+//   - 400 Bad Request: если тело или состояние некорректны
+//   - 401 Unauthorized: если подпись отсутствует, некорректна или устарела
+//   - 404 Not Found: если платёж не найден
+//   - 409 Conflict: если платёж уже завершён с другим результатом
+//   - 413 Request Entity Too Large: если тело больше payments.max_callback_bytes
+//   - 429 Too Many Requests: если превышено ограничение по IP-адресу
+//   - 503 Service Unavailable: если секрет подписи не настроен
+func PaymentCallback(db *gorm.DB, cfg *config.PaymentsConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.CallbackSecret == "" {
+			apiversion.Error(c, http.StatusServiceUnavailable, "payments_disabled", "payment callbacks are not configured")
+			return
+		}
+
+		// Тело читается до проверки подписи, поэтому его размер ограничен
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxCallbackBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				apiversion.Error(c, http.StatusRequestEntityTooLarge, "request_too_large", "request body too large")
+				return
+			}
+			badRequest(c, "Invalid request body")
+			return
+		}
+		if !webhooks.Verify(cfg.CallbackSecret, c.GetHeader(PaymentSignatureHeader), body, time.Now(), cfg.CallbackTolerance) {
+			apiversion.Error(c, http.StatusUnauthorized, "invalid_signature", "invalid payment callback signature")
+			return
+		}
+
+		var req dto.PaymentCallbackRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		payment, err := services.ConfirmPayment(c.Request.Context(), db, req.ExternalID, req.Status, req.FailureCode)
+		logger.AddFields(c.Request.Context(), zap.String("external_id", req.ExternalID), zap.String("outcome", outcome(err)))
+		if err != nil {
+			status := paymentErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				logger.FromContext(c.Request.Context()).Error("Request failed", zap.String("route", c.FullPath()), zap.Error(err))
+			}
+			respondError(c, status, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, newPaymentResponse(c, payment))
+	}
+}
+
+// bindPaymentRequest разбирает тело запроса платежа в формате версии API; сумма - в копейках
+func bindPaymentRequest(c *gin.Context) (dto.PaymentRequestV2, error) {
+	if apiversion.Get(c) != apiversion.V1 {
+		var req dto.PaymentRequestV2
+		err := c.ShouldBindJSON(&req)
+		return req, err
+	}
+
+	var req dto.PaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return dto.PaymentRequestV2{}, err
+	}
+	return dto.PaymentRequestV2{
+		Wallet:     req.Wallet,
+		Amount:     dto.Amount(convertMoneyToInt(req.Amount)),
+		ExternalID: req.ExternalID,
+		Nonce:      req.Nonce,
+		Signature:  req.Signature,
+	}, nil
+}
+
+// paymentErrorStatus возвращает HTTP-статус для ошибки операции с платежом
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrWalletNotFound), errors.Is(err, services.ErrPaymentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrPaymentExists), errors.Is(err, services.ErrPaymentFinalized):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrNotEnoughMoney), errors.Is(err, services.ErrWalletFrozen),
+		errors.Is(err, services.ErrInvalidNonce):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrInvalidAmount), errors.Is(err, services.ErrInvalidExternalID),
+		errors.Is(err, services.ErrSystemWallet), errors.Is(err, services.ErrInvalidPaymentOutcome),
+		errors.Is(err, services.ErrSignatureRequired), errors.Is(err, services.ErrInvalidSignature):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// newPaymentResponse преобразует модель платежа в ответ версии API запроса
+func newPaymentResponse(c *gin.Context, payment *models.Payment) any {
+	if apiversion.Get(c) != apiversion.V1 {
+		return dto.NewPaymentResponseV2(payment)
+	}
+	return dto.PaymentResponse{
+		ID:                  payment.ID,
+		Kind:                payment.Kind,
+		Wallet:              payment.WalletAddress,
+		Amount:              convertMoneyToFloat(payment.Amount),
+		Status:              payment.Status,
+		ExternalID:          payment.ExternalID,
+		TransactionID:       payment.TransactionID,
+		RefundTransactionID: payment.RefundTransactionID,
+		FailureCode:         payment.FailureCode,
+		CreatedAt:           payment.CreatedAt,
+		UpdatedAt:           payment.UpdatedAt,
+	}
+}
@@ -0,0 +1,164 @@
+// Package handlers содержит обработчики HTTP-запросов для потока транзакций
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/events"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// StreamTransactions отправляет зафиксированные транзакции по мере их появления (Server-Sent Events).
+//
+// GET /api/transactions/stream?wallet=ADDRESS
+//
+// Параметры запроса:
+//   - wallet (string, необязательный, можно повторять) — только транзакции, где кошелёк является отправителем или получателем
+//
+// Заголовки запроса:
+//   - Last-Event-ID — ID последней полученной транзакции; сначала отправляются транзакции с большим ID
+//     (не более stream.replay_limit), затем новые. Браузерный EventSource передаёт его при переподключении сам
+//
+// Каждая транзакция отправляется событием "transaction" с полем id, равным ID транзакции, и JSON-телом
+// в том же формате, что и GET /api/transactions. Пока событий нет, раз в stream.heartbeat_interval
+// отправляется комментарий. Если клиент не успевает читать события, поток закрывается,
+// и клиент догоняет пропущенное при переподключении.
+//
+// Возвращаются только транзакции кошельков, доступных субъекту запроса (для администраторов - все транзакции).
+//
+// Ответ:
+//   - 200 OK: поток text/event-stream
+//   - 400 Bad Request: если Last-Event-ID некорректен
+//   - 403 Forbidden: если кошелёк из параметра wallet недоступен субъекту запроса
+func StreamTransactions(db *gorm.DB, cfg *config.StreamConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+
+		wallets, err := streamWallets(p, c.QueryArray("wallet"))
+		if err != nil {
+			respondError(c, http.StatusForbidden, err)
+			return
+		}
+
+		var lastID uint64
+		if v := c.GetHeader("Last-Event-ID"); v != "" {
+			if lastID, err = strconv.ParseUint(v, 10, 64); err != nil {
+				badRequest(c, "Invalid Last-Event-ID value")
+				return
+			}
+		}
+
+		ctx := c.Request.Context()
+		// Подписка оформляется до догоняющей выборки, чтобы не потерять транзакции между ними
+		sub := events.Subscribe(cfg.BufferSize)
+		defer sub.Close()
+
+		var replay []models.Transaction
+		if lastID > 0 {
+			replay, err = services.GetTransactionsAfter(ctx, db, uint(lastID), wallets, cfg.ReplayLimit)
+			if err != nil {
+				logger.FromContext(ctx).Error("Request failed", zap.String("route", c.FullPath()), zap.Error(err))
+				respondError(c, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		// Поток живёт дольше server.write_timeout, поэтому дедлайн записи продлевается перед каждым событием
+		rc := http.NewResponseController(c.Writer)
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		send := func(payload string) bool {
+			_ = rc.SetWriteDeadline(time.Now().Add(cfg.HeartbeatInterval * 2))
+			if _, err := fmt.Fprint(c.Writer, payload); err != nil {
+				return false
+			}
+			return rc.Flush() == nil
+		}
+
+		// Комментарий сразу отправляет заголовки, чтобы клиент увидел установленный поток
+		if !send(": connected\n\n") {
+			return
+		}
+
+		sent := make(map[uint]struct{}, len(replay))
+		for _, t := range replay {
+			if !send(transactionEvent(t)) {
+				return
+			}
+			sent[t.ID] = struct{}{}
+		}
+		logger.AddFields(ctx, zap.Int("replayed", len(replay)))
+
+		heartbeat := time.NewTicker(cfg.HeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-sub.C:
+				if !ok {
+					if sub.Dropped() {
+						logger.FromContext(ctx).Warn("Slow stream client disconnected")
+					}
+					return
+				}
+				if _, dup := sent[e.Transaction.ID]; dup || !streamMatches(e.Transaction, wallets) {
+					continue
+				}
+				if !send(transactionEvent(e.Transaction)) {
+					return
+				}
+			case <-heartbeat.C:
+				if !send(": ping\n\n") {
+					return
+				}
+			}
+		}
+	}
+}
+
+// streamWallets возвращает кошельки, по которым фильтруется поток (nil - без фильтра)
+//
+// Запрошенные кошельки должны быть доступны субъекту; если они не указаны,
+// поток ограничивается всеми доступными субъекту кошельками
+func streamWallets(p *auth.Principal, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return p.ReadableWallets(), nil
+	}
+	for _, address := range requested {
+		if !p.CanRead(address) {
+			return nil, services.ErrForbidden
+		}
+	}
+	return requested, nil
+}
+
+// streamMatches проверяет, проходит ли транзакция фильтр по кошелькам
+func streamMatches(t models.Transaction, wallets []string) bool {
+	return wallets == nil || slices.Contains(wallets, t.From) || slices.Contains(wallets, t.To)
+}
+
+// transactionEvent форматирует транзакцию как событие SSE
+func transactionEvent(t models.Transaction) string {
+	data, _ := json.Marshal(t)
+	return fmt.Sprintf("id: %d\nevent: transaction\ndata: %s\n\n", t.ID, data)
+}
@@ -2,9 +2,14 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/apiversion"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
 	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
 	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"net/http"
 	"strconv"
@@ -12,35 +17,105 @@ import (
 
 // GetLastTransactions возвращает список последних N транзакций.
 //
-// GET /api/transactions?count=N
+// GET /api/transactions?count=N[&reference=R][&status=S]
 //
 // Параметры запроса:
 //   - count (int) — количество транзакций для возврата
+//   - reference (string) — если задан, только транзакции с этим внешним идентификатором
+//   - status (string) — если задан, только транзакции в этом состоянии; "all" - все, включая отклонённые
+//     попытки (без параметра отклонённые попытки не возвращаются)
+//
+// Возвращаются только транзакции кошельков, доступных субъекту запроса
+// (для администраторов - все транзакции).
 //
 // Ответ:
-//   - 200 OK: JSON-массив транзакций
-//   - 400 Bad Request: если параметр count некорректный
+//   - 200 OK: JSON-массив транзакций (в API v2 - dto.TransactionResponse с суммой строкой)
+//   - 400 Bad Request: если параметр count или status некорректный
 //   - 500 Internal Server Error: если произошла ошибка при получении данных
 func GetLastTransactions(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		count, err := strconv.Atoi(c.Query("count"))
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
 
 		if count <= 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid count value"})
+			badRequest(c, "Invalid count value")
+			return
+		}
+
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+
+		transactions, err := services.GetLastNTransactions(c.Request.Context(), db, count, p.ReadableWallets(), c.Query("reference"), c.Query("status"))
+		if errors.Is(err, services.ErrInvalidStatusFilter) {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Error("Request failed", zap.String("route", c.FullPath()), zap.Error(err))
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if apiversion.Get(c) == apiversion.V1 {
+			c.JSON(http.StatusOK, transactions)
+			return
+		}
+		resp := make([]dto.TransactionResponse, len(transactions))
+		for i := range transactions {
+			resp[i] = dto.NewTransactionResponse(&transactions[i])
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// GetTransaction возвращает транзакцию по идентификатору
+//
+// GET /api/transactions/{id}
+//
+// Транзакции, в которых не участвуют доступные субъекту запроса кошельки, не отличаются от несуществующих.
+//
+// Ответ:
+//   - 200 OK: транзакция (в API v2 - dto.TransactionResponse)
+//   - 400 Bad Request: если идентификатор некорректен
+//   - 404 Not Found: если транзакция не найдена
+func GetTransaction(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			badRequest(c, "Invalid transaction id")
 			return
 		}
 
-		transactions, err := services.GetLastNTransactions(db, count)
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+
+		transaction, err := services.GetTransaction(c.Request.Context(), db, uint(id))
+		if err == nil && !p.CanRead(transaction.From) && !p.CanRead(transaction.To) {
+			err = services.ErrTransactionNotFound
+		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			status := http.StatusInternalServerError
+			if errors.Is(err, services.ErrTransactionNotFound) {
+				status = http.StatusNotFound
+			} else {
+				logger.FromContext(c.Request.Context()).Error("Request failed", zap.String("route", c.FullPath()), zap.Error(err))
+			}
+			respondError(c, status, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, transactions)
+		if apiversion.Get(c) == apiversion.V1 {
+			c.JSON(http.StatusOK, transaction)
+			return
+		}
+		c.JSON(http.StatusOK, dto.NewTransactionResponse(transaction))
 	}
 }
 
@@ -53,33 +128,127 @@ func GetLastTransactions(db *gorm.DB) gin.HandlerFunc {
 //	{
 //	  "from": "wallet1",
 //	  "to": "wallet2",
-//	  "amount": 33.3
+//	  "amount": 33.3,
+//	  "nonce": 1,
+//	  "signature": "9f0c..."
 //	}
 //
 // Поля:
 //   - from (string) — адрес отправителя
 //   - to (string) — адрес получателя
 //   - amount (float64) — сумма перевода в условных единицах (например, 33.3 = 33.3 у.е.)
+//   - nonce, signature — подпись Ed25519 сообщения services.TransferMessage, включающего memo, reference
+//     и metadata (обязательна, если у кошелька отправителя зарегистрирован ключ)
+//   - memo, reference, metadata — необязательные комментарий, внешний идентификатор (уникален для кошелька
+//     отправителя) и метаданные; возвращаются в ответах с транзакцией
+//
+// Списывать средства можно только с кошельков, привязанных к ключу доступа субъекта запроса.
+//
+// В API v2 сумма передаётся строкой ("amount": "33.30").
 //
 // Ответ:
 //   - 200 OK: {"status": "sent"} — если перевод успешен
 //   - 400 Bad Request: если входные данные некорректны или недостаточно средств
+//   - 403 Forbidden: если субъект запроса не владеет кошельком отправителя
+//
+// Ответ API v2:
+//   - 201 Created: созданная транзакция (dto.TransactionResponse)
+//   - 400 Bad Request: если входные данные или подпись некорректны
+//   - 403 Forbidden: если субъект запроса не владеет кошельком отправителя
+//   - 404 Not Found: если кошелёк отправителя или получателя не найден
+//   - 409 Conflict: если внешний идентификатор уже использован кошельком отправителя
+//   - 422 Unprocessable Entity: если недостаточно средств, nonce уже использован или кошелёк заморожен
 func SendTransaction(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req dto.TransactionRequest
+		req, err := bindTransactionRequest(c)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		logger.AddFields(c.Request.Context(), zap.String("from", req.From), zap.String("to", req.To))
+
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+		if !p.CanDebit(req.From) {
+			logger.AddFields(c.Request.Context(), zap.String("outcome", outcome(services.ErrForbidden)))
+			respondError(c, http.StatusForbidden, services.ErrForbidden)
+			return
+		}
+
+		var sig *services.Signature
+		if req.Signature != "" {
+			sig = &services.Signature{Nonce: req.Nonce, Value: req.Signature}
+		}
 
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		details := services.TransferDetails{Memo: req.Memo, Reference: req.Reference, Metadata: req.Metadata}
+		transaction, err := services.TransferMoney(c.Request.Context(), db, req.From, req.To, int64(req.Amount), details, sig)
+		logger.AddFields(c.Request.Context(), zap.String("outcome", outcome(err)))
+		if apiversion.Get(c) == apiversion.V1 {
+			if err != nil {
+				respondError(c, http.StatusBadRequest, err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"status": "sent"})
 			return
 		}
 
-		err := services.TransferMoney(db, req.From, req.To, convertMoneyToInt(req.Amount))
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			respondError(c, transferErrorStatus(err), err)
 			return
 		}
+		c.JSON(http.StatusCreated, dto.NewTransactionResponse(transaction))
+	}
+}
+
+// bindTransactionRequest разбирает тело запроса перевода в формате версии API
+//
+// В API v1 сумма передаётся числом в у.е., в API v2 - строкой; в обоих случаях
+// результат содержит сумму в копейках
+func bindTransactionRequest(c *gin.Context) (dto.TransactionRequestV2, error) {
+	if apiversion.Get(c) != apiversion.V1 {
+		var req dto.TransactionRequestV2
+		err := c.ShouldBindJSON(&req)
+		return req, err
+	}
 
-		c.JSON(http.StatusOK, gin.H{"status": "sent"})
+	var req dto.TransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return dto.TransactionRequestV2{}, err
+	}
+	return dto.TransactionRequestV2{
+		From:      req.From,
+		To:        req.To,
+		Amount:    dto.Amount(convertMoneyToInt(req.Amount)),
+		Nonce:     req.Nonce,
+		Signature: req.Signature,
+		Memo:      req.Memo,
+		Reference: req.Reference,
+		Metadata:  req.Metadata,
+	}, nil
+}
+
+// transferErrorStatus возвращает HTTP-статус для ошибки перевода в API v2
+//
+// В API v1 любая ошибка перевода возвращается как 400 Bad Request
+func transferErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrSenderNotFound), errors.Is(err, services.ErrReceiverNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrNotEnoughMoney), errors.Is(err, services.ErrInvalidNonce),
+		errors.Is(err, services.ErrWalletFrozen):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrInvalidAmount), errors.Is(err, services.ErrSelfTransfer),
+		errors.Is(err, services.ErrSignatureRequired), errors.Is(err, services.ErrInvalidSignature),
+		errors.Is(err, services.ErrSystemWallet), errors.Is(err, services.ErrMemoTooLong),
+		errors.Is(err, services.ErrInvalidReference), errors.Is(err, services.ErrInvalidMetadata):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrReferenceExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
 	}
 }
 
@@ -89,3 +258,21 @@ func SendTransaction(db *gorm.DB) gin.HandlerFunc {
 func convertMoneyToInt(value float64) int64 {
 	return int64(value * 100)
 }
+
+// principal возвращает субъекта запроса; если он не определён, отвечает 401 Unauthorized
+func principal(c *gin.Context) (*auth.Principal, bool) {
+	p, ok := auth.FromContext(c.Request.Context())
+	if !ok {
+		apiversion.Error(c, http.StatusUnauthorized, "unauthenticated", "unauthenticated")
+		return nil, false
+	}
+	return p, true
+}
+
+// outcome возвращает результат операции для журнала доступа: "success" или код ошибки сервисного слоя
+func outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	return services.ErrorCode(err)
+}
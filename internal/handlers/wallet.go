@@ -2,8 +2,14 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/apiversion"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
 	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"net/http"
 )
@@ -17,18 +23,319 @@ import (
 //
 // Ответ:
 //   - 200 OK: {"balance": 100.50} — если кошелек найден, баланс возвращается в формате float64 (у.е)
+//   - 403 Forbidden: {"error": "wallet not permitted"} — если кошелёк недоступен субъекту запроса
 //   - 500 Internal Server Error: {"error": "wallet not found"} — если кошелек не найден или произошла ошибка
+//
+// В API v2 баланс возвращается строкой ({"balance": "100.50"}), а для отсутствующего кошелька - 404 Not Found
 func GetBalance(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		address := c.Param("address")
 
-		balance, err := services.GetWalletBalance(db, address)
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+		if !p.CanRead(address) {
+			logger.AddFields(c.Request.Context(), zap.String("address", address), zap.String("outcome", outcome(services.ErrForbidden)))
+			respondError(c, http.StatusForbidden, services.ErrForbidden)
+			return
+		}
+
+		balance, err := services.GetWalletBalance(c.Request.Context(), db, address)
+		logger.AddFields(c.Request.Context(), zap.String("address", address), zap.String("outcome", outcome(err)))
+		v1 := apiversion.Get(c) == apiversion.V1
+		if err != nil {
+			if !errors.Is(err, services.ErrWalletNotFound) {
+				logger.FromContext(c.Request.Context()).Error("Request failed", zap.String("route", c.FullPath()), zap.Error(err))
+			}
+			if v1 {
+				respondError(c, http.StatusInternalServerError, err)
+			} else {
+				respondError(c, walletErrorStatus(err), err)
+			}
+			return
+		}
+
+		if v1 {
+			c.JSON(http.StatusOK, gin.H{"balance": convertMoneyToFloat(balance)})
+			return
+		}
+		c.JSON(http.StatusOK, dto.BalanceResponseV2{Balance: dto.Amount(balance)})
+	}
+}
+
+// GetWallet возвращает информацию о кошельке, включая публичный ключ и текущий nonce
+//
+// GET /api/wallet/{address}
+//
+// Ответ:
+//   - 200 OK: {"address": "...", "balance": 100.5, "public_key": "...", "nonce": 3, "frozen": false}
+//   - 403 Forbidden: если кошелёк недоступен субъекту запроса
+//   - 404 Not Found: если кошелёк не найден
+func GetWallet(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		address := c.Param("address")
+
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+		if !p.CanRead(address) {
+			respondError(c, http.StatusForbidden, services.ErrForbidden)
+			return
+		}
+
+		wallet, err := services.GetWallet(c.Request.Context(), db, address)
+		if err != nil {
+			respondError(c, walletErrorStatus(err), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, newWalletResponse(c, wallet))
+	}
+}
+
+// CreateWallet создаёт кошелёк с нулевым балансом
+//
+// POST /api/admin/wallets
+//
+// Тело запроса (JSON, необязательно):
+//
+//	{"public_key": "<ключ Ed25519 в hex>"}
+//
+// Если ключ передан, адрес кошелька - SHA-256 от ключа, и переводы с кошелька должны быть подписаны.
+//
+// Ответ:
+//   - 201 Created: созданный кошелёк
+//   - 400 Bad Request: если ключ некорректен
+//   - 409 Conflict: если кошелёк для ключа уже существует
+func CreateWallet(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CreateWalletRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respondError(c, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		wallet, err := services.CreateWallet(c.Request.Context(), db, req.PublicKey)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondError(c, walletErrorStatus(err), err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, newWalletResponse(c, wallet))
+	}
+}
+
+// RegisterWalletKey регистрирует публичный ключ Ed25519 для кошелька
+//
+// PUT /api/wallet/{address}/key
+//
+// Тело запроса (JSON):
+//
+//	{"public_key": "<ключ Ed25519 в hex>"}
+//
+// Зарегистрировать ключ может только субъект, которому разрешено списание с кошелька.
+//
+// Ответ:
+//   - 200 OK: кошелёк с зарегистрированным ключом
+//   - 400 Bad Request: если ключ некорректен
+//   - 403 Forbidden: если субъект запроса не владеет кошельком
+//   - 404 Not Found: если кошелёк не найден
+//   - 409 Conflict: если ключ уже зарегистрирован
+func RegisterWalletKey(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		address := c.Param("address")
+
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+		if !p.CanDebit(address) {
+			respondError(c, http.StatusForbidden, services.ErrForbidden)
+			return
+		}
+
+		var req dto.RegisterKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"balance": convertMoneyToFloat(balance)})
+		wallet, err := services.RegisterPublicKey(c.Request.Context(), db, address, req.PublicKey)
+		if err != nil {
+			respondError(c, walletErrorStatus(err), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, newWalletResponse(c, wallet))
+	}
+}
+
+// FreezeWallet замораживает кошелёк: переводы с него и на него отклоняются с ошибкой "wallet frozen"
+//
+// POST /api/admin/wallets/{address}/freeze
+//
+// Ответ:
+//   - 200 OK: кошелёк после заморозки
+//   - 404 Not Found: если кошелёк не найден
+func FreezeWallet(db *gorm.DB) gin.HandlerFunc {
+	return setWalletFrozen(db, true)
+}
+
+// UnfreezeWallet снимает заморозку с кошелька
+//
+// DELETE /api/admin/wallets/{address}/freeze
+//
+// Ответ:
+//   - 200 OK: кошелёк после снятия заморозки
+//   - 404 Not Found: если кошелёк не найден
+func UnfreezeWallet(db *gorm.DB) gin.HandlerFunc {
+	return setWalletFrozen(db, false)
+}
+
+// setWalletFrozen возвращает обработчик, устанавливающий заморозку кошелька
+func setWalletFrozen(db *gorm.DB, frozen bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		address := c.Param("address")
+
+		wallet, err := services.SetWalletFrozen(c.Request.Context(), db, address, frozen)
+		logger.AddFields(c.Request.Context(), zap.String("address", address), zap.String("outcome", outcome(err)))
+		if err != nil {
+			respondError(c, walletErrorStatus(err), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, newWalletResponse(c, wallet))
+	}
+}
+
+// AdjustWallet выполняет ручную корректировку баланса кошелька
+//
+// POST /api/admin/wallets/{address}/adjustments
+//
+// Тело запроса (JSON):
+//
+//	{"direction": "credit", "amount": 15.5, "reason_code": "goodwill", "note": "INC-42: компенсация"}
+//
+// Корректировка проводится транзакцией типа "adjustment" с системным кошельком капитала
+// (см. services.AdjustBalance) и отображается в истории транзакций кошелька.
+// В API v2 сумма передаётся строкой ("amount": "15.50").
+//
+// Ответ:
+//   - 201 Created: транзакция корректировки (в API v2 - dto.TransactionResponse)
+//   - 400 Bad Request: если направление, сумма, код причины или комментарий некорректны
+//   - 404 Not Found: если кошелёк не найден
+//   - 422 Unprocessable Entity: если при списании недостаточно средств или кошелёк заморожен
+func AdjustWallet(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		address := c.Param("address")
+
+		req, err := bindAdjustmentRequest(c)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		delta := int64(req.Amount)
+		switch {
+		case delta <= 0:
+			respondError(c, http.StatusBadRequest, services.ErrInvalidAmount)
+			return
+		case req.Direction == "debit":
+			delta = -delta
+		case req.Direction != "credit":
+			badRequest(c, `direction must be "credit" or "debit"`)
+			return
+		}
+
+		transaction, err := services.AdjustBalance(c.Request.Context(), db, address, delta, req.ReasonCode, req.Note)
+		logger.AddFields(c.Request.Context(), zap.String("address", address), zap.String("outcome", outcome(err)))
+		if err != nil {
+			status := adjustmentErrorStatus(err)
+			if status == http.StatusInternalServerError {
+				logger.FromContext(c.Request.Context()).Error("Request failed", zap.String("route", c.FullPath()), zap.Error(err))
+			}
+			respondError(c, status, err)
+			return
+		}
+
+		if apiversion.Get(c) == apiversion.V1 {
+			c.JSON(http.StatusCreated, transaction)
+			return
+		}
+		c.JSON(http.StatusCreated, dto.NewTransactionResponse(transaction))
+	}
+}
+
+// bindAdjustmentRequest разбирает тело запроса корректировки в формате версии API; сумма - в копейках
+func bindAdjustmentRequest(c *gin.Context) (dto.AdjustmentRequestV2, error) {
+	if apiversion.Get(c) != apiversion.V1 {
+		var req dto.AdjustmentRequestV2
+		err := c.ShouldBindJSON(&req)
+		return req, err
+	}
+
+	var req dto.AdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return dto.AdjustmentRequestV2{}, err
+	}
+	return dto.AdjustmentRequestV2{
+		Direction:  req.Direction,
+		Amount:     dto.Amount(convertMoneyToInt(req.Amount)),
+		ReasonCode: req.ReasonCode,
+		Note:       req.Note,
+	}, nil
+}
+
+// adjustmentErrorStatus возвращает HTTP-статус для ошибки ручной корректировки
+func adjustmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrWalletNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrNotEnoughMoney), errors.Is(err, services.ErrWalletFrozen):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrInvalidAmount), errors.Is(err, services.ErrReasonRequired),
+		errors.Is(err, services.ErrInvalidReasonCode), errors.Is(err, services.ErrNoteTooLong),
+		errors.Is(err, services.ErrSystemWallet):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// walletErrorStatus возвращает HTTP-статус для ошибки операции с кошельком
+func walletErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrWalletNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidPublicKey):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrWalletExists), errors.Is(err, services.ErrPublicKeyAlreadySet):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// newWalletResponse преобразует модель кошелька в ответ версии API запроса
+func newWalletResponse(c *gin.Context, wallet *models.Wallet) any {
+	if apiversion.Get(c) != apiversion.V1 {
+		return dto.WalletResponseV2{
+			Address:   wallet.Address,
+			Balance:   dto.Amount(wallet.Balance),
+			PublicKey: wallet.PublicKey,
+			Nonce:     wallet.Nonce,
+			Frozen:    wallet.Frozen,
+		}
+	}
+	return dto.WalletResponse{
+		Address:   wallet.Address,
+		Balance:   convertMoneyToFloat(wallet.Balance),
+		PublicKey: wallet.PublicKey,
+		Nonce:     wallet.Nonce,
+		Frozen:    wallet.Frozen,
 	}
 }
 
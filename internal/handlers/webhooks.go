@@ -0,0 +1,238 @@
+// Package handlers содержит обработчики HTTP-запросов для управления вебхуками
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"github.com/normalniydada/test_task_infotecs/internal/webhooks"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+// Ограничения размера страницы событий вебхука
+const (
+	defaultWebhookEventsLimit = 50
+	maxWebhookEventsLimit     = 500
+)
+
+// CreateWebhook создаёт подписку на события кошелька
+//
+// POST /api/webhooks
+//
+// Тело запроса (JSON):
+//
+//	{
+//	  "wallet": "e240d825...",
+//	  "url": "https://example.com/hooks/wallet",
+//	  "event_types": ["transfer.incoming", "transfer.outgoing"],
+//	  "secret": "..."
+//	}
+//
+// Подписываться можно только на кошельки, привязанные к ключу доступа субъекта запроса.
+//
+// Ответ:
+//   - 201 Created: подписка, включая секрет подписи в поле "secret" (показывается один раз)
+//   - 400 Bad Request: если тело запроса некорректно, URL не http(s), тип события неизвестен или кошелёк не существует
+//   - 403 Forbidden: если субъект запроса не владеет кошельком
+func CreateWebhook(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CreateWebhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+		if !p.CanDebit(req.Wallet) {
+			respondError(c, http.StatusForbidden, services.ErrForbidden)
+			return
+		}
+
+		sub, err := services.CreateWebhook(c.Request.Context(), db, req.Wallet, req.URL, req.EventTypes, req.Secret)
+		if err != nil {
+			respondError(c, webhookErrorStatus(err), err)
+			return
+		}
+
+		resp := dto.NewWebhookResponse(sub)
+		resp.Secret = sub.Secret
+		c.JSON(http.StatusCreated, resp)
+	}
+}
+
+// ListWebhooks возвращает действующие подписки кошельков, доступных субъекту запроса
+//
+// GET /api/webhooks
+//
+// Ответ:
+//   - 200 OK: JSON-массив подписок без секретов
+func ListWebhooks(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+
+		subs, err := services.ListWebhooks(c.Request.Context(), db, p.ReadableWallets())
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		resp := make([]dto.WebhookResponse, len(subs))
+		for i := range subs {
+			resp[i] = dto.NewWebhookResponse(&subs[i])
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// DeleteWebhook удаляет подписку; недоставленные события подписки больше не доставляются
+//
+// DELETE /api/webhooks/{id}
+//
+// Ответ:
+//   - 200 OK: {"status": "deleted"}
+//   - 404 Not Found: если подписка не найдена, уже удалена или принадлежит чужому кошельку
+func DeleteWebhook(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := ownedWebhookID(c, db)
+		if !ok {
+			return
+		}
+
+		if err := services.DeleteWebhook(c.Request.Context(), db, id); err != nil {
+			respondError(c, webhookErrorStatus(err), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+	}
+}
+
+// ListWebhookEvents возвращает последние события подписки вместе с попытками доставки
+//
+// GET /api/webhooks/{id}/events?limit=N
+//
+// Параметры запроса:
+//   - limit (int, необязательный) — число событий, от 1 до 500 (по умолчанию 50)
+//
+// Ответ:
+//   - 200 OK: JSON-массив событий, начиная с самых новых
+//   - 400 Bad Request: если limit некорректен
+//   - 404 Not Found: если подписка не найдена или принадлежит чужому кошельку
+func ListWebhookEvents(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := defaultWebhookEventsLimit
+		if v := c.Query("limit"); v != "" {
+			var err error
+			if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxWebhookEventsLimit {
+				badRequest(c, "Invalid limit value")
+				return
+			}
+		}
+
+		id, ok := ownedWebhookID(c, db)
+		if !ok {
+			return
+		}
+
+		events, err := services.ListWebhookEvents(c.Request.Context(), db, id, limit)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		resp := make([]dto.WebhookEventResponse, len(events))
+		for i := range events {
+			resp[i] = dto.NewWebhookEventResponse(&events[i])
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// RedeliverWebhookEvent немедленно повторяет доставку события, в том числе доставленного
+// или недоставленного после исчерпания попыток
+//
+// POST /api/webhooks/events/{id}/redeliver
+//
+// Ответ:
+//   - 200 OK: результат попытки; при ответе получателя кодом 2xx событие помечается доставленным
+//   - 404 Not Found: если событие не найдено, его подписка удалена или принадлежит чужому кошельку
+func RedeliverWebhookEvent(db *gorm.DB, dispatcher *webhooks.Dispatcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			badRequest(c, "Invalid event id")
+			return
+		}
+
+		p, ok := principal(c)
+		if !ok {
+			return
+		}
+
+		event, sub, err := services.GetWebhookEvent(c.Request.Context(), db, id)
+		if err == nil && (sub.DeletedAt != nil || !p.CanDebit(sub.WalletAddress)) {
+			err = services.ErrWebhookEventNotFound
+		}
+		if err != nil {
+			respondError(c, webhookErrorStatus(err), err)
+			return
+		}
+
+		delivery, err := dispatcher.Redeliver(c.Request.Context(), event, sub)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewWebhookDeliveryResponse(delivery))
+	}
+}
+
+// ownedWebhookID разбирает идентификатор подписки из пути и проверяет, что субъект запроса
+// владеет её кошельком; при ошибке отвечает клиенту
+//
+// Чужие подписки не отличаются от несуществующих, чтобы не раскрывать их наличие
+func ownedWebhookID(c *gin.Context, db *gorm.DB) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		badRequest(c, "Invalid webhook id")
+		return 0, false
+	}
+
+	p, ok := principal(c)
+	if !ok {
+		return 0, false
+	}
+
+	sub, err := services.GetWebhook(c.Request.Context(), db, uint(id))
+	if err == nil && !p.CanDebit(sub.WalletAddress) {
+		err = services.ErrWebhookNotFound
+	}
+	if err != nil {
+		respondError(c, webhookErrorStatus(err), err)
+		return 0, false
+	}
+	return sub.ID, true
+}
+
+// webhookErrorStatus возвращает HTTP-статус для ошибки операции с вебхуками
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound), errors.Is(err, services.ErrWebhookEventNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidWebhookURL), errors.Is(err, services.ErrInvalidEventType),
+		errors.Is(err, services.ErrWalletNotFound):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,140 @@
+// Package health отвечает за проверку готовности приложения обслуживать запросы
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/workers"
+	"gorm.io/gorm"
+	"sync/atomic"
+	"time"
+)
+
+// Статусы проверок
+const (
+	StatusOK   = "ok"   // Проверка пройдена
+	StatusFail = "fail" // Проверка не пройдена
+)
+
+// Check содержит результат проверки одной зависимости
+//
+// Поля:
+//   - Status (string) — "ok" или "fail"
+//   - Error (string) — описание проблемы, если проверка не пройдена
+//   - Details (any) — дополнительная информация о зависимости
+type Check struct {
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Report содержит итог проверки готовности и результаты по каждой зависимости
+type Report struct {
+	Status string           `json:"status"`
+	Checks map[string]Check `json:"checks"`
+}
+
+// Ready сообщает, пройдены ли все проверки
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// Checker проверяет зависимости приложения: базу данных, миграции и фоновые задачи
+type Checker struct {
+	db           *gorm.DB
+	bg           *workers.Group
+	tables       []any
+	shuttingDown atomic.Bool
+}
+
+// NewChecker создаёт проверку готовности
+//
+// Параметры:
+//   - db (*gorm.DB): подключение к базе данных
+//   - bg (*workers.Group): фоновые задачи, которые должны выполняться
+//   - tables ([]any): модели, таблицы которых должны быть созданы миграцией
+func NewChecker(db *gorm.DB, bg *workers.Group, tables []any) *Checker {
+	return &Checker{db: db, bg: bg, tables: tables}
+}
+
+// SetShuttingDown переводит приложение в состояние остановки, после чего проверка готовности не проходит
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Check выполняет все проверки готовности
+//
+// Логика работы:
+//  1. Проверка, что приложение не находится в процессе остановки
+//  2. Ping базы данных с измерением задержки
+//  3. Проверка наличия таблиц всех моделей
+//  4. Проверка, что все фоновые задачи выполняются
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]Check, 4)}
+	add := func(name string, check Check) {
+		if check.Status != StatusOK {
+			report.Status = StatusFail
+		}
+		report.Checks[name] = check
+	}
+
+	if c.shuttingDown.Load() {
+		add("shutdown", Check{Status: StatusFail, Error: "server is shutting down"})
+	} else {
+		add("shutdown", Check{Status: StatusOK})
+	}
+
+	add("database", c.checkDatabase(ctx))
+	add("migrations", c.checkMigrations(ctx))
+	add("workers", c.checkWorkers())
+
+	return report
+}
+
+// checkDatabase выполняет ping базы данных
+func (c *Checker) checkDatabase(ctx context.Context) Check {
+	pdb, err := c.db.DB()
+	if err != nil {
+		return Check{Status: StatusFail, Error: err.Error()}
+	}
+
+	start := time.Now()
+	if err = pdb.PingContext(ctx); err != nil {
+		return Check{Status: StatusFail, Error: err.Error()}
+	}
+
+	stats := pdb.Stats()
+	return Check{Status: StatusOK, Details: map[string]any{
+		"latency_ms":       time.Since(start).Milliseconds(),
+		"open_connections": stats.OpenConnections,
+		"in_use":           stats.InUse,
+	}}
+}
+
+// checkMigrations проверяет, что таблицы всех моделей существуют
+func (c *Checker) checkMigrations(ctx context.Context) Check {
+	migrator := c.db.WithContext(ctx).Migrator()
+
+	var missing []string
+	for _, model := range c.tables {
+		if !migrator.HasTable(model) {
+			missing = append(missing, fmt.Sprintf("%T", model))
+		}
+	}
+
+	if len(missing) > 0 {
+		return Check{Status: StatusFail, Error: "missing tables", Details: missing}
+	}
+	return Check{Status: StatusOK}
+}
+
+// checkWorkers проверяет, что все зарегистрированные фоновые задачи выполняются
+func (c *Checker) checkWorkers() Check {
+	running := c.bg.Running()
+	for name, ok := range running {
+		if !ok {
+			return Check{Status: StatusFail, Error: "worker " + name + " is not running", Details: running}
+		}
+	}
+	return Check{Status: StatusOK, Details: running}
+}
@@ -0,0 +1,117 @@
+// Package metrics содержит метрики Prometheus для HTTP-запросов, переводов, вебхуков и базы данных
+package metrics
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"strconv"
+	"time"
+)
+
+// namespace - общий префикс имён метрик приложения
+const namespace = "wallet"
+
+var (
+	// httpRequests - количество HTTP-запросов по методу, маршруту и статусу ответа
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// httpDuration - время обработки HTTP-запросов по методу, маршруту и статусу ответа
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// transfers - количество переводов по результату (success, not_enough_money, sender_not_found, ...)
+	transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Total number of transfer attempts by outcome.",
+	}, []string{"outcome"})
+
+	// transferredAmount - сумма успешно переведённых средств в минимальных единицах валюты
+	transferredAmount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transferred_amount_cents_total",
+		Help:      "Total amount of successfully transferred money in cents.",
+	})
+
+	// transferLockWait - время ожидания блокировок `FOR UPDATE` на кошельках при переводе
+	transferLockWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transfer_lock_wait_seconds",
+		Help:      "Time spent acquiring wallet row locks in TransferMoney.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	})
+
+	// webhookDeliveries - количество попыток доставки вебхуков по результату (delivered, retry, failed, manual_failed)
+	webhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Total number of webhook delivery attempts by outcome.",
+	}, []string{"outcome"})
+)
+
+// Middleware собирает количество и время обработки HTTP-запросов
+//
+// В качестве маршрута используется шаблон пути Gin (например, `/api/wallet/:address/balance`),
+// чтобы адреса кошельков не порождали новые временные ряды
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler отдаёт метрики в формате Prometheus
+//
+// GET /metrics
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// RegisterDBStats регистрирует метрики пула соединений `database/sql`
+// (открытые, занятые и простаивающие соединения, ожидания соединений)
+func RegisterDBStats(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// ObserveTransfer учитывает попытку перевода с указанным результатом
+//
+// Параметры:
+//   - outcome (string): результат перевода ("success" или код ошибки)
+//   - amount (int64): сумма перевода; учитывается только для успешных переводов
+func ObserveTransfer(outcome string, amount int64) {
+	transfers.WithLabelValues(outcome).Inc()
+	if outcome == "success" {
+		transferredAmount.Add(float64(amount))
+	}
+}
+
+// ObserveLockWait учитывает время ожидания блокировок кошельков при переводе
+func ObserveLockWait(d time.Duration) {
+	transferLockWait.Observe(d.Seconds())
+}
+
+// ObserveWebhookDelivery учитывает попытку доставки вебхука с указанным результатом
+func ObserveWebhookDelivery(outcome string) {
+	webhookDeliveries.WithLabelValues(outcome).Inc()
+}
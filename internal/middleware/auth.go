@@ -0,0 +1,109 @@
+// Package middleware содержит промежуточные обработчики Gin, общие для всех маршрутов
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/apiversion"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader - заголовок с ключом доступа к API
+const APIKeyHeader = "X-API-Key"
+
+// bearerPrefix - префикс JWT в заголовке Authorization
+const bearerPrefix = "Bearer "
+
+// Authenticate определяет субъекта запроса по JWT или ключу доступа и сохраняет его в контексте
+//
+// Если аутентификация выключена (`auth.enabled: false`), всем запросам назначается
+// субъект auth.Anonymous без ограничений.
+//
+// Параметры:
+//   - db (*gorm.DB): подключение к базе данных для поиска ключей доступа
+//   - cfg (*config.AuthConfig): настройки аутентификации
+//   - jwtVerifier (*auth.JWTVerifier): проверка JWT; nil, если JWT не принимаются
+//
+// Логика работы:
+//  1. Если передан заголовок "Authorization: Bearer", проверяется JWT, роль берётся из утверждений токена
+//  2. Иначе проверяется ключ из заголовка `X-API-Key`: административный ключ получает роль admin,
+//     остальные - operator с ограничением привязанными кошельками
+//
+// Ответ при ошибке:
+//   - 401 Unauthorized: если учётные данные не переданы, ключ не существует или отозван, токен некорректен
+//   - 500 Internal Server Error: если не удалось проверить ключ
+func Authenticate(db *gorm.DB, cfg *config.AuthConfig, jwtVerifier *auth.JWTVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled {
+			setPrincipal(c, auth.Anonymous)
+			return
+		}
+
+		if header := c.GetHeader("Authorization"); jwtVerifier != nil && strings.HasPrefix(header, bearerPrefix) {
+			p, err := jwtVerifier.Verify(strings.TrimPrefix(header, bearerPrefix))
+			if err != nil {
+				logger.FromContext(c.Request.Context()).Info("JWT rejected", zap.Error(err))
+				apiversion.Abort(c, http.StatusUnauthorized, "invalid_token", auth.ErrInvalidToken.Error())
+				return
+			}
+			setPrincipal(c, p)
+			return
+		}
+
+		raw := c.GetHeader(APIKeyHeader)
+		if raw == "" {
+			apiversion.Abort(c, http.StatusUnauthorized, "missing_credentials", "missing credentials")
+			return
+		}
+
+		key, err := services.AuthenticateAPIKey(c.Request.Context(), db, raw)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				apiversion.Abort(c, http.StatusUnauthorized, services.ErrorCode(err), err.Error())
+				return
+			}
+			logger.FromContext(c.Request.Context()).Error("API key lookup failed", zap.Error(err))
+			apiversion.Abort(c, http.StatusInternalServerError, "internal", err.Error())
+			return
+		}
+
+		setPrincipal(c, auth.NewAPIKeyPrincipal(key))
+	}
+}
+
+// RequireRole пропускает только запросы субъектов с указанной ролью или ролью выше неё
+//
+// Ответ при ошибке:
+//   - 403 Forbidden: если у субъекта нет требуемой роли
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := auth.FromContext(c.Request.Context())
+		if !ok || !p.HasRole(role) {
+			apiversion.Abort(c, http.StatusForbidden, "role_required", role+" role required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// setPrincipal сохраняет субъекта в контексте запроса вместе со сведениями для журнала аудита
+// и добавляет его в логгер запроса
+func setPrincipal(c *gin.Context, p *auth.Principal) {
+	ctx := auth.WithPrincipal(c.Request.Context(), p)
+	ctx = audit.WithMeta(ctx, audit.Meta{
+		Actor:     p.Subject,
+		RequestID: GetRequestID(ctx),
+		SourceIP:  c.ClientIP(),
+	})
+	logger.AddFields(ctx, zap.String("principal", p.Subject), zap.String("role", p.Role))
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
@@ -0,0 +1,57 @@
+// Package middleware содержит промежуточные обработчики Gin, общие для всех маршрутов
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/apiversion"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"time"
+)
+
+// AccessLog пишет структурированную запись журнала доступа через логгер запроса
+//
+// Запись содержит статус, длительность, размер ответа и все поля, добавленные обработчиком
+// через `logger.AddFields` (например, адреса кошельков и результат операции).
+// Уровень записи: Error для 5xx, Warn для 4xx, Info для остальных ответов
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int("bytes", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		zLog := logger.FromContext(c.Request.Context())
+		switch {
+		case status >= http.StatusInternalServerError:
+			zLog.Error("Request completed", fields...)
+		case status >= http.StatusBadRequest:
+			zLog.Warn("Request completed", fields...)
+		default:
+			zLog.Info("Request completed", fields...)
+		}
+	}
+}
+
+// Recovery перехватывает панику в обработчике, пишет её в логгер запроса вместе со стеком
+// и отвечает 500 Internal Server Error
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logger.FromContext(c.Request.Context()).Error("Panic recovered",
+			zap.Any("panic", recovered),
+			zap.Stack("stack"),
+		)
+		apiversion.Abort(c, http.StatusInternalServerError, "internal", "internal server error")
+	})
+}
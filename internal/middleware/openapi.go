@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/apiversion"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+// ValidateOpenAPI проверяет запросы и ответы по OpenAPI-описанию API
+//
+// Параметры:
+//   - router (routers.Router): маршрутизатор операций описания (см. openapi.Load)
+//   - cfg (*config.OpenAPIConfig): какие проверки включены
+//
+// Логика работы:
+//  1. Поиск операции по методу и пути; запросы к не описанным маршрутам (потоки, WebSocket,
+//     администрирование) пропускаются без проверок
+//  2. Проверка параметров и тела запроса; при нарушении ответ 400 Bad Request без вызова обработчика
+//  3. При включённой проверке ответов ответ обработчика буферизуется и сверяется со статусом,
+//     заголовками и схемой тела из описания; ответ с нарушением, в том числе со статусом, которого
+//     нет в описании операции, логируется и заменяется на 500
+//
+// Аутентификация проверяется middleware.Authenticate, поэтому схемы безопасности описания здесь не проверяются
+func ValidateOpenAPI(router routers.Router, cfg *config.OpenAPIConfig) gin.HandlerFunc {
+	opts := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc, IncludeResponseStatus: true}
+
+	return func(c *gin.Context) {
+		if !cfg.ValidateRequests && !cfg.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    opts,
+		}
+		if cfg.ValidateRequests {
+			// Тело запроса после проверки восстанавливается для обработчика
+			if err := openapi3filter.ValidateRequest(ctx, input); err != nil {
+				logger.AddFields(ctx, zap.String("openapi_request_error", err.Error()))
+				apiversion.Abort(c, http.StatusBadRequest, "invalid_request", requestValidationMessage(err))
+				return
+			}
+		}
+
+		if !cfg.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		output := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 w.Status(),
+			Header:                 w.Header(),
+			Options:                opts,
+		}
+		output.SetBodyBytes(w.body.Bytes())
+		if err := openapi3filter.ValidateResponse(ctx, output); err != nil {
+			logger.FromContext(ctx).Error("Response does not match OpenAPI specification",
+				zap.Int("status", w.Status()),
+				zap.Error(err),
+			)
+			apiversion.Error(c, http.StatusInternalServerError, "internal", "response does not match api specification")
+			return
+		}
+
+		c.Writer.WriteHeaderNow()
+		_, _ = c.Writer.Write(w.body.Bytes())
+	}
+}
+
+// requestValidationMessage возвращает краткое описание нарушения: параметр или поле тела и причину,
+// без схемы и значения целиком
+func requestValidationMessage(err error) string {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return "request does not match api specification"
+	}
+
+	reason := reqErr.Reason
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		reason = schemaErr.Reason
+		if path := schemaErr.JSONPointer(); len(path) > 0 {
+			reason = strings.Join(path, ".") + ": " + reason
+		}
+	} else if reason == "" && reqErr.Err != nil {
+		reason = reqErr.Err.Error()
+	}
+
+	switch {
+	case reqErr.Parameter != nil:
+		return fmt.Sprintf("invalid %s parameter %q: %s", reqErr.Parameter.In, reqErr.Parameter.Name, reason)
+	case reqErr.RequestBody != nil:
+		return "invalid request body: " + reason
+	default:
+		return reason
+	}
+}
+
+// bufferedWriter накапливает тело ответа, не отправляя его клиенту, до проверки по описанию.
+// Статус и заголовки запоминаются исходным gin.ResponseWriter и отправляются при первой записи тела
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write записывает данные в буфер
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+// WriteString записывает строку в буфер
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// WriteHeaderNow откладывает отправку заголовков до проверки ответа
+func (w *bufferedWriter) WriteHeaderNow() {}
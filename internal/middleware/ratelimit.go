@@ -0,0 +1,111 @@
+// Package middleware содержит промежуточные обработчики Gin, общие для всех маршрутов
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/normalniydada/test_task_infotecs/internal/apiversion"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/ratelimit"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// IPRateLimit ограничивает частоту запросов с одного IP-адреса
+//
+// Подключается до Authenticate, чтобы ограничение действовало и на запросы с отсутствующими
+// или неверными учётными данными (подбор ключей доступа)
+func IPRateLimit(store ratelimit.Store, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rateLimit(c, store, "ip:"+c.ClientIP(), limit)
+	}
+}
+
+// ClientRateLimit ограничивает частоту запросов одного клиента
+//
+// Клиент определяется по субъекту запроса (ключ доступа или JWT); для анонимных запросов -
+// по IP-адресу. Должен подключаться после Authenticate.
+func ClientRateLimit(store ratelimit.Store, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if p, ok := auth.FromContext(c.Request.Context()); ok && p != auth.Anonymous {
+			key = p.Subject
+		}
+		rateLimit(c, store, "client:"+key, limit)
+	}
+}
+
+// SenderRateLimit ограничивает частоту списаний с одного кошелька независимо от клиента
+//
+// Адрес кошелька читается из поля field JSON-тела запроса ("from" у переводов, "wallet" у выводов);
+// переводы и выводы с одного кошелька расходуют общую корзину. Тело восстанавливается
+// для последующих обработчиков. Запросы без отправителя и запросы субъектов, которым нельзя
+// списывать средства с кошелька, пропускаются без списания токена - их отклонит обработчик;
+// иначе любой клиент мог бы исчерпать лимит чужого кошелька. Должен подключаться после Authenticate.
+// Тело больше maxBodyBytes отклоняется с 413 Request Entity Too Large.
+func SenderRateLimit(store ratelimit.Store, limit ratelimit.Limit, maxBodyBytes int64, field string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, ok := ReadBody(c, maxBodyBytes)
+		if !ok {
+			return
+		}
+		var req map[string]json.RawMessage
+		var from string
+		if json.Unmarshal(body, &req) != nil || json.Unmarshal(req[field], &from) != nil || from == "" {
+			c.Next()
+			return
+		}
+		if p, ok := auth.FromContext(c.Request.Context()); !ok || !p.CanDebit(from) {
+			c.Next()
+			return
+		}
+		rateLimit(c, store, "sender:"+from, limit)
+	}
+}
+
+// ReadBody читает тело запроса не больше maxBytes байт и восстанавливает его для последующих обработчиков
+//
+// Если тело больше maxBytes, отвечает 413 Request Entity Too Large, при ошибке чтения - 400 Bad Request;
+// в обоих случаях возвращает false
+func ReadBody(c *gin.Context, maxBytes int64) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apiversion.Abort(c, http.StatusRequestEntityTooLarge, "request_too_large", "request body too large")
+			return nil, false
+		}
+		apiversion.Abort(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return nil, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
+
+// rateLimit списывает токен из корзины key и отвечает 429 Too Many Requests с заголовком Retry-After,
+// если токенов нет
+//
+// Ошибка хранилища не блокирует запрос: она записывается в лог, и запрос пропускается
+func rateLimit(c *gin.Context, store ratelimit.Store, key string, limit ratelimit.Limit) {
+	res, err := store.Take(c.Request.Context(), key, limit)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Error("Rate limit check failed", zap.String("key", key), zap.Error(err))
+		c.Next()
+		return
+	}
+
+	if !res.Allowed {
+		retryAfter := int(math.Ceil(res.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		logger.AddFields(c.Request.Context(), zap.String("rate_limited", key))
+		apiversion.Abort(c, http.StatusTooManyRequests, "rate_limited", "rate limit exceeded")
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,67 @@
+// Package middleware содержит промежуточные обработчики Gin, общие для всех маршрутов
+package middleware
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/normalniydada/test_task_infotecs/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader - заголовок с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength - максимальная длина принимаемого от клиента идентификатора запроса
+const maxRequestIDLength = 128
+
+// requestIDKey - ключ идентификатора запроса в контексте
+type requestIDKey struct{}
+
+// RequestID назначает запросу идентификатор и сохраняет в контексте логгер запроса
+//
+// Логика работы:
+//  1. Использование `X-Request-ID` из запроса, если он корректен, иначе генерация UUID
+//  2. Возврат идентификатора клиенту в заголовке `X-Request-ID`
+//  3. Сохранение идентификатора и логгера запроса (request_id, method, route, client_ip, trace_id)
+//     в контексте `c.Request.Context()`
+func RequestID(zLog *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, id)
+
+		ctx := context.WithValue(c.Request.Context(), requestIDKey{}, id)
+		ctx = logger.ToContext(ctx, zLog.With(
+			zap.String("request_id", id),
+			zap.String("method", c.Request.Method),
+			zap.String("route", c.FullPath()),
+			zap.String("client_ip", c.ClientIP()),
+		))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// GetRequestID возвращает идентификатор запроса из контекста или пустую строку
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID проверяет, что идентификатор от клиента не пуст, не слишком длинный
+// и состоит только из печатных ASCII-символов
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
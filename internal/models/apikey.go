@@ -0,0 +1,47 @@
+// Package models содержит описание структур базы данных для работы с ключами доступа к API
+package models
+
+import "time"
+
+// APIKey представляет модель ключа доступа к API
+//
+// Сам ключ не хранится: в базе данных сохраняется только его SHA-256 хеш
+//
+// Поля:
+//   - ID (uint) — уникальный идентификатор ключа (первичный ключ)
+//   - Name (string) — описание владельца ключа
+//   - Prefix (string) — первые символы ключа для его опознания в списках и логах
+//   - KeyHash (string) — SHA-256 хеш ключа в hex (уникальный индекс)
+//   - Admin (bool) — разрешает управление ключами и чтение всех кошельков
+//   - Wallets ([]APIKeyWallet) — кошельки, с которых владелец ключа может списывать средства
+//   - CreatedAt (time.Time) — время создания ключа
+//   - RevokedAt (*time.Time) — время отзыва ключа; nil, если ключ действует
+type APIKey struct {
+	ID        uint           `gorm:"primaryKey"`                   // Уникальный идентификатор ключа
+	Name      string         `gorm:"not null"`                     // Описание владельца ключа
+	Prefix    string         `gorm:"size:16;not null"`             // Первые символы ключа
+	KeyHash   string         `gorm:"size:64;not null;uniqueIndex"` // SHA-256 хеш ключа
+	Admin     bool           `gorm:"not null;default:false"`       // Административный ключ
+	Wallets   []APIKeyWallet `gorm:"foreignKey:APIKeyID"`          // Разрешённые кошельки
+	CreatedAt time.Time      `gorm:"autoCreateTime"`               // Время создания
+	RevokedAt *time.Time     `gorm:"index:idx_api_key_revoked_at"` // Время отзыва
+}
+
+// APIKeyWallet связывает ключ доступа с кошельком, с которого разрешено списание средств
+//
+// Поля:
+//   - APIKeyID (uint) — идентификатор ключа
+//   - WalletAddress (string) — адрес кошелька
+type APIKeyWallet struct {
+	APIKeyID      uint   `gorm:"primaryKey"`         // Идентификатор ключа
+	WalletAddress string `gorm:"primaryKey;size:64"` // Адрес кошелька
+}
+
+// Addresses возвращает адреса кошельков, привязанных к ключу
+func (k *APIKey) Addresses() []string {
+	addresses := make([]string, len(k.Wallets))
+	for i, w := range k.Wallets {
+		addresses[i] = w.WalletAddress
+	}
+	return addresses
+}
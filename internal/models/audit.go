@@ -0,0 +1,35 @@
+// Package models содержит описание структур базы данных для журнала аудита
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog представляет запись журнала аудита об изменении состояния
+//
+// Записи только добавляются: изменение и удаление запрещены триггером базы данных
+//
+// Поля:
+//   - ID (uint64) — уникальный идентификатор записи (первичный ключ)
+//   - CreatedAt (time.Time) — время записи
+//   - Actor (string) — субъект, выполнивший действие (например, "api_key:12", "jwt:alice", "system")
+//   - RequestID (string) — идентификатор HTTP-запроса (`X-Request-ID`), если действие выполнено через API
+//   - SourceIP (string) — IP-адрес клиента
+//   - Action (string) — действие, например "transfer.create" или "api_key.revoke"
+//   - EntityType (string) — тип изменённой сущности: "transaction", "wallet", "api_key"
+//   - EntityID (string) — идентификатор изменённой сущности
+//   - Before (json.RawMessage) — состояние до изменения (JSON), пусто для созданных сущностей
+//   - After (json.RawMessage) — состояние после изменения (JSON)
+type AuditLog struct {
+	ID         uint64          `gorm:"primaryKey"`                                          // Уникальный идентификатор записи
+	CreatedAt  time.Time       `gorm:"autoCreateTime;index:idx_audit_created_at"`           // Время записи
+	Actor      string          `gorm:"size:255;not null;index:idx_audit_actor"`             // Субъект действия
+	RequestID  string          `gorm:"size:128;index:idx_audit_request_id"`                 // Идентификатор запроса
+	SourceIP   string          `gorm:"size:64"`                                             // IP-адрес клиента
+	Action     string          `gorm:"size:64;not null;index:idx_audit_action"`             // Действие
+	EntityType string          `gorm:"size:64;not null;index:idx_audit_entity,priority:1"`  // Тип сущности
+	EntityID   string          `gorm:"size:128;not null;index:idx_audit_entity,priority:2"` // Идентификатор сущности
+	Before     json.RawMessage `gorm:"type:jsonb"`                                          // Состояние до изменения
+	After      json.RawMessage `gorm:"type:jsonb"`                                          // Состояние после изменения
+}
@@ -0,0 +1,89 @@
+// Package dto содержит структуры для передачи данных DTO в API
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAmountFormat - ошибка: сумма не является десятичной строкой с не более чем двумя знаками после запятой
+var ErrInvalidAmountFormat = errors.New(`amount must be a decimal string with at most 2 fractional digits, e.g. "33.30"`)
+
+// Amount - сумма в копейках, передаваемая в JSON строкой в у.е. с двумя знаками после запятой.
+//
+// Используется в API v2 вместо float64, чтобы суммы не теряли точность при разборе клиентами.
+//
+// Пример: Amount(3330) <-> "33.30"
+type Amount int64
+
+// String возвращает сумму в у.е. с двумя знаками после запятой
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return sign + strconv.FormatInt(v/100, 10) + "." + strconv.FormatInt(v%100/10, 10) + strconv.FormatInt(v%10, 10)
+}
+
+// MarshalJSON кодирует сумму строкой, например "33.30"
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON разбирает сумму из строки вида "33", "33.3" или "33.30" без преобразования во float
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return ErrInvalidAmountFormat
+	}
+	v, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// ParseAmount разбирает сумму в у.е. из десятичной строки
+//
+// Возвращает:
+//   - Amount: сумма в копейках
+//   - error: ErrInvalidAmountFormat, если строка не является числом с не более чем двумя знаками после запятой
+func ParseAmount(s string) (Amount, error) {
+	negative := strings.HasPrefix(s, "-")
+	units, cents, hasCents := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if units == "" || !digits(units) || (hasCents && (cents == "" || len(cents) > 2 || !digits(cents))) {
+		return 0, ErrInvalidAmountFormat
+	}
+
+	v, err := strconv.ParseInt(units, 10, 64)
+	if err != nil || v > (1<<63-1)/100-1 {
+		return 0, ErrInvalidAmountFormat
+	}
+	v *= 100
+	if hasCents {
+		c, _ := strconv.ParseInt(cents, 10, 64)
+		if len(cents) == 1 {
+			c *= 10
+		}
+		v += c
+	}
+	if negative {
+		v = -v
+	}
+	return Amount(v), nil
+}
+
+// digits сообщает, состоит ли строка только из десятичных цифр
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
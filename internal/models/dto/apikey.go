@@ -0,0 +1,58 @@
+// Package dto содержит структуры для передачи данных DTO в API
+package dto
+
+import (
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"time"
+)
+
+// CreateAPIKeyRequest представляет тело запроса на создание ключа доступа.
+//
+// Используется в API `POST /api/admin/keys`.
+//
+// Пример JSON-запроса:
+//
+//	{
+//	  "name": "billing-service",
+//	  "admin": false,
+//	  "wallets": ["e240d825..."]
+//	}
+type CreateAPIKeyRequest struct {
+	Name    string   `json:"name" binding:"required"`
+	Admin   bool     `json:"admin"`
+	Wallets []string `json:"wallets"`
+}
+
+// SetAPIKeyWalletsRequest представляет тело запроса на замену кошельков ключа.
+//
+// Используется в API `PUT /api/admin/keys/{id}/wallets`.
+type SetAPIKeyWalletsRequest struct {
+	Wallets []string `json:"wallets"`
+}
+
+// APIKeyResponse представляет ключ доступа в ответах API.
+//
+// Поле Key заполняется только при создании ключа: позже получить его значение невозможно.
+type APIKeyResponse struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Key       string     `json:"key,omitempty"`
+	Admin     bool       `json:"admin"`
+	Wallets   []string   `json:"wallets"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// NewAPIKeyResponse преобразует модель ключа в ответ API
+func NewAPIKeyResponse(key *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Admin:     key.Admin,
+		Wallets:   key.Addresses(),
+		CreatedAt: key.CreatedAt,
+		RevokedAt: key.RevokedAt,
+	}
+}
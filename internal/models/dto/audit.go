@@ -0,0 +1,38 @@
+// Package dto содержит структуры для передачи данных DTO в API
+package dto
+
+import (
+	"encoding/json"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"time"
+)
+
+// AuditLogResponse представляет запись журнала аудита в ответах API `GET /api/admin/audit`.
+type AuditLogResponse struct {
+	ID         uint64          `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id,omitempty"`
+	SourceIP   string          `json:"source_ip,omitempty"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+// NewAuditLogResponse преобразует запись журнала аудита в ответ API
+func NewAuditLogResponse(entry *models.AuditLog) AuditLogResponse {
+	return AuditLogResponse{
+		ID:         entry.ID,
+		CreatedAt:  entry.CreatedAt,
+		Actor:      entry.Actor,
+		RequestID:  entry.RequestID,
+		SourceIP:   entry.SourceIP,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Before:     entry.Before,
+		After:      entry.After,
+	}
+}
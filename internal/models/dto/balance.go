@@ -0,0 +1,45 @@
+// Package dto содержит структуры для передачи данных DTO в API
+package dto
+
+// Типы сообщений WebSocket-подписки на балансы
+const (
+	BalanceActionSubscribe   = "subscribe"   // Клиент: подписаться на кошельки
+	BalanceActionUnsubscribe = "unsubscribe" // Клиент: отписаться от кошельков
+
+	BalanceMessageSubscribed   = "subscribed"   // Сервер: подписка оформлена, текущие балансы
+	BalanceMessageUnsubscribed = "unsubscribed" // Сервер: подписка отменена
+	BalanceMessageBalance      = "balance"      // Сервер: баланс кошелька изменился
+	BalanceMessageError        = "error"        // Сервер: ошибка обработки сообщения клиента
+)
+
+// BalanceSubscriptionRequest представляет сообщение клиента WebSocket `GET /api/ws/balances`.
+//
+// Поля:
+//   - Action (string) — "subscribe" или "unsubscribe"
+//   - Wallets ([]string) — адреса кошельков
+type BalanceSubscriptionRequest struct {
+	Action  string   `json:"action"`
+	Wallets []string `json:"wallets"`
+}
+
+// BalanceMessage представляет сообщение сервера WebSocket `GET /api/ws/balances`.
+//
+// Поля:
+//   - Type (string) — тип сообщения: subscribed, unsubscribed, balance, error
+//   - Wallets ([]string) — кошельки, к которым относится подтверждение подписки или отписки
+//   - Balances (map[string]float64) — текущие балансы кошельков при подписке, в у.е.
+//   - Wallet (string) — кошелёк, баланс которого изменился
+//   - Balance (*float64) — новый баланс в у.е.
+//   - Delta (float64) — изменение баланса в у.е. (отрицательное при списании)
+//   - TransactionID (uint) — ID транзакции, изменившей баланс
+//   - Error (string) — описание ошибки
+type BalanceMessage struct {
+	Type          string             `json:"type"`
+	Wallets       []string           `json:"wallets,omitempty"`
+	Balances      map[string]float64 `json:"balances,omitempty"`
+	Wallet        string             `json:"wallet,omitempty"`
+	Balance       *float64           `json:"balance,omitempty"`
+	Delta         float64            `json:"delta,omitempty"`
+	TransactionID uint               `json:"transaction_id,omitempty"`
+	Error         string             `json:"error,omitempty"`
+}
@@ -0,0 +1,114 @@
+// Package dto содержит структуры для передачи данных DTO в API
+package dto
+
+import (
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"time"
+)
+
+// PaymentRequest представляет тело запроса на пополнение или вывод средств.
+//
+// Используется в API `POST /api/deposits` и `POST /api/withdrawals`.
+//
+// Поля:
+//   - Wallet (string) — адрес кошелька
+//   - Amount (float64) — сумма в у.е.
+//   - ExternalID (string) — идентификатор платежа у платёжного провайдера, уникален
+//   - Nonce (uint64) — nonce подписанного вывода, больше nonce предыдущего подписанного списания с кошелька
+//   - Signature (string) — подпись Ed25519 в hex; обязательна для вывода, если у кошелька зарегистрирован ключ
+//
+// Пример JSON-запроса:
+//
+//	{
+//	  "wallet": "e240d825...",
+//	  "amount": 250,
+//	  "external_id": "psp-7f3a91"
+//	}
+type PaymentRequest struct {
+	Wallet     string  `json:"wallet"`
+	Amount     float64 `json:"amount"`
+	ExternalID string  `json:"external_id"`
+	Nonce      uint64  `json:"nonce,omitempty"`
+	Signature  string  `json:"signature,omitempty"`
+}
+
+// PaymentRequestV2 представляет тело запроса на пополнение или вывод средств в API v2; сумма передаётся строкой
+type PaymentRequestV2 struct {
+	Wallet     string `json:"wallet"`
+	Amount     Amount `json:"amount"`
+	ExternalID string `json:"external_id"`
+	Nonce      uint64 `json:"nonce,omitempty"`
+	Signature  string `json:"signature,omitempty"`
+}
+
+// PaymentCallbackRequest представляет уведомление платёжного провайдера о результате платежа.
+//
+// Используется в API `POST /api/payments/callback`.
+//
+// Поля:
+//   - ExternalID (string) — идентификатор платежа у провайдера
+//   - Status (string) — "completed" или "failed"
+//   - FailureCode (string) — причина отказа (для failed)
+type PaymentCallbackRequest struct {
+	ExternalID  string `json:"external_id"`
+	Status      string `json:"status"`
+	FailureCode string `json:"failure_code"`
+}
+
+// PaymentResponse представляет пополнение или вывод средств в ответах API.
+//
+// Поля:
+//   - ID (uint) — идентификатор платежа
+//   - Kind (string) — "deposit" или "withdrawal"
+//   - Wallet (string) — адрес кошелька
+//   - Amount (float64) — сумма в у.е.
+//   - Status (string) — "pending", "completed" или "failed"
+//   - ExternalID (string) — идентификатор платежа у провайдера
+//   - TransactionID (*uint) — транзакция пополнения или вывода
+//   - RefundTransactionID (*uint) — транзакция возврата средств отклонённого вывода
+//   - FailureCode (string) — причина отказа
+type PaymentResponse struct {
+	ID                  uint      `json:"id"`
+	Kind                string    `json:"kind"`
+	Wallet              string    `json:"wallet"`
+	Amount              float64   `json:"amount"`
+	Status              string    `json:"status"`
+	ExternalID          string    `json:"external_id"`
+	TransactionID       *uint     `json:"transaction_id,omitempty"`
+	RefundTransactionID *uint     `json:"refund_transaction_id,omitempty"`
+	FailureCode         string    `json:"failure_code,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// PaymentResponseV2 представляет платёж в ответах API v2; сумма передаётся строкой в у.е.
+type PaymentResponseV2 struct {
+	ID                  uint      `json:"id"`
+	Kind                string    `json:"kind"`
+	Wallet              string    `json:"wallet"`
+	Amount              Amount    `json:"amount"`
+	Status              string    `json:"status"`
+	ExternalID          string    `json:"external_id"`
+	TransactionID       *uint     `json:"transaction_id,omitempty"`
+	RefundTransactionID *uint     `json:"refund_transaction_id,omitempty"`
+	FailureCode         string    `json:"failure_code,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// NewPaymentResponseV2 преобразует модель платежа в ответ API v2
+func NewPaymentResponseV2(p *models.Payment) PaymentResponseV2 {
+	return PaymentResponseV2{
+		ID:                  p.ID,
+		Kind:                p.Kind,
+		Wallet:              p.WalletAddress,
+		Amount:              Amount(p.Amount),
+		Status:              p.Status,
+		ExternalID:          p.ExternalID,
+		TransactionID:       p.TransactionID,
+		RefundTransactionID: p.RefundTransactionID,
+		FailureCode:         p.FailureCode,
+		CreatedAt:           p.CreatedAt,
+		UpdatedAt:           p.UpdatedAt,
+	}
+}
@@ -1,6 +1,11 @@
 // Package dto содержит структуры для передачи данных DTO в API
 package dto
 
+import (
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"time"
+)
+
 // TransactionRequest представляет тело запроса для перевода средств.
 //
 // Используется в API `POST /api/send`.
@@ -9,16 +14,132 @@ package dto
 //   - From (string) — адрес кошелька отправителя
 //   - To (string) — адрес кошелька получателя
 //   - Amount (float64) — сумма перевода в у.е
+//   - Nonce (uint64) — nonce подписанного перевода, больше nonce предыдущего перевода с кошелька
+//   - Signature (string) — подпись Ed25519 в hex; обязательна, если у кошелька отправителя зарегистрирован ключ
+//   - Memo (string) — комментарий к переводу, не длиннее 500 символов
+//   - Reference (string) — внешний идентификатор перевода, уникален для кошелька отправителя, не длиннее 128 байт
+//   - Metadata (map[string]string) — не более 20 пар; ключ не длиннее 40 байт, значение - 500 байт
 //
 // Пример JSON-запроса:
 //
 //	{
 //	  "from": "wallet1",
 //	  "to": "wallet2",
-//	  "amount": 33.3
+//	  "amount": 33.3,
+//	  "reference": "order-1842",
+//	  "metadata": {"order_id": "1842"}
 //	}
 type TransactionRequest struct {
-	From   string  `json:"from"`
-	To     string  `json:"to"`
-	Amount float64 `json:"amount"`
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	Amount    float64           `json:"amount"`
+	Nonce     uint64            `json:"nonce,omitempty"`
+	Signature string            `json:"signature,omitempty"`
+	Memo      string            `json:"memo,omitempty"`
+	Reference string            `json:"reference,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// TransactionRequestV2 представляет тело запроса для перевода средств в API v2.
+//
+// Используется в API `POST /api/v2/send`. Отличается от TransactionRequest типом суммы:
+// строка в у.е. с не более чем двумя знаками после запятой.
+//
+// Пример JSON-запроса:
+//
+//	{
+//	  "from": "wallet1",
+//	  "to": "wallet2",
+//	  "amount": "33.30"
+//	}
+type TransactionRequestV2 struct {
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	Amount    Amount            `json:"amount"`
+	Nonce     uint64            `json:"nonce,omitempty"`
+	Signature string            `json:"signature,omitempty"`
+	Memo      string            `json:"memo,omitempty"`
+	Reference string            `json:"reference,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// TransactionResponse представляет транзакцию в ответах API v2.
+//
+// Поля:
+//   - ID (uint) — идентификатор транзакции
+//   - From (string) — адрес кошелька отправителя
+//   - To (string) — адрес кошелька получателя
+//   - Amount (Amount) — сумма перевода строкой в у.е.
+//   - CreatedAt (time.Time) — время создания транзакции
+//   - Type (string) — тип транзакции: "transfer", "adjustment", "deposit", "withdrawal" или "refund"
+//   - ReasonCode, Note (string) — код причины и комментарий ручной корректировки
+//   - Status (string) — состояние: "pending", "completed", "failed" или "reversed"
+//   - ErrorCode (string) — код ошибки отклонённой попытки перевода
+//   - Memo, Reference (string), Metadata (map[string]string) — комментарий, внешний идентификатор и метаданные отправителя
+type TransactionResponse struct {
+	ID         uint              `json:"id"`
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	Amount     Amount            `json:"amount"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Type       string            `json:"type"`
+	ReasonCode string            `json:"reason_code,omitempty"`
+	Note       string            `json:"note,omitempty"`
+	Status     string            `json:"status"`
+	ErrorCode  string            `json:"error_code,omitempty"`
+	Memo       string            `json:"memo,omitempty"`
+	Reference  string            `json:"reference,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// NewTransactionResponse преобразует модель транзакции в ответ API v2
+func NewTransactionResponse(t *models.Transaction) TransactionResponse {
+	return TransactionResponse{
+		ID:         t.ID,
+		From:       t.From,
+		To:         t.To,
+		Amount:     Amount(t.Amount),
+		CreatedAt:  t.CreatedAt,
+		Type:       t.Type,
+		ReasonCode: t.ReasonCode,
+		Note:       t.Note,
+		Status:     t.Status,
+		ErrorCode:  t.ErrorCode,
+		Memo:       t.Memo,
+		Reference:  t.Reference,
+		Metadata:   t.Metadata,
+	}
+}
+
+// AdjustmentRequest представляет тело запроса ручной корректировки баланса.
+//
+// Используется в API `POST /api/admin/wallets/{address}/adjustments`.
+//
+// Поля:
+//   - Direction (string) — "credit" (начисление) или "debit" (списание)
+//   - Amount (float64) — сумма корректировки в у.е., больше 0
+//   - ReasonCode (string) — код причины из models.AdjustmentReasonCodes
+//   - Note (string) — комментарий администратора
+//
+// Пример JSON-запроса:
+//
+//	{
+//	  "direction": "credit",
+//	  "amount": 15.5,
+//	  "reason_code": "goodwill",
+//	  "note": "INC-42: компенсация за задержку перевода"
+//	}
+type AdjustmentRequest struct {
+	Direction  string  `json:"direction"`
+	Amount     float64 `json:"amount"`
+	ReasonCode string  `json:"reason_code"`
+	Note       string  `json:"note"`
+}
+
+// AdjustmentRequestV2 представляет тело запроса ручной корректировки баланса в API v2; сумма передаётся строкой
+type AdjustmentRequestV2 struct {
+	Direction  string `json:"direction"`
+	Amount     Amount `json:"amount"`
+	ReasonCode string `json:"reason_code"`
+	Note       string `json:"note"`
 }
@@ -0,0 +1,53 @@
+// Package dto содержит структуры для передачи данных DTO в API
+package dto
+
+// CreateWalletRequest представляет тело запроса на создание кошелька.
+//
+// Используется в API `POST /api/admin/wallets`.
+//
+// Поля:
+//   - PublicKey (string) — публичный ключ Ed25519 в hex; если задан, адрес кошелька выводится из ключа
+type CreateWalletRequest struct {
+	PublicKey string `json:"public_key"`
+}
+
+// RegisterKeyRequest представляет тело запроса на регистрацию ключа кошелька.
+//
+// Используется в API `PUT /api/wallet/{address}/key`.
+type RegisterKeyRequest struct {
+	PublicKey string `json:"public_key" binding:"required"`
+}
+
+// WalletResponse представляет кошелёк в ответах API.
+//
+// Поля:
+//   - Address (string) — адрес кошелька
+//   - Balance (float64) — баланс в у.е.
+//   - PublicKey (string) — публичный ключ Ed25519 в hex, если зарегистрирован
+//   - Nonce (uint64) — nonce последнего подписанного перевода или вывода; следующее списание должно использовать большее значение
+//   - Frozen (bool) — кошелёк заморожен, переводы с него и на него запрещены
+type WalletResponse struct {
+	Address   string  `json:"address"`
+	Balance   float64 `json:"balance"`
+	PublicKey string  `json:"public_key,omitempty"`
+	Nonce     uint64  `json:"nonce"`
+	Frozen    bool    `json:"frozen"`
+}
+
+// WalletResponseV2 представляет кошелёк в ответах API v2; баланс передаётся строкой в у.е.
+type WalletResponseV2 struct {
+	Address   string `json:"address"`
+	Balance   Amount `json:"balance"`
+	PublicKey string `json:"public_key,omitempty"`
+	Nonce     uint64 `json:"nonce"`
+	Frozen    bool   `json:"frozen"`
+}
+
+// BalanceResponseV2 представляет баланс кошелька в ответе API v2 `GET /api/v2/wallet/{address}/balance`.
+//
+// Пример JSON-ответа:
+//
+//	{"balance": "100.50"}
+type BalanceResponseV2 struct {
+	Balance Amount `json:"balance"`
+}
@@ -0,0 +1,114 @@
+// Package dto содержит структуры для передачи данных DTO в API
+package dto
+
+import (
+	"encoding/json"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"time"
+)
+
+// CreateWebhookRequest представляет тело запроса на создание подписки на вебхуки.
+//
+// Используется в API `POST /api/webhooks`.
+//
+// Пример JSON-запроса:
+//
+//	{
+//	  "wallet": "e240d825...",
+//	  "url": "https://example.com/hooks/wallet",
+//	  "event_types": ["transfer.incoming"],
+//	  "secret": ""
+//	}
+//
+// Если event_types пуст, подписка получает все типы событий; если secret пуст, он генерируется.
+type CreateWebhookRequest struct {
+	Wallet     string   `json:"wallet" binding:"required"`
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+// WebhookResponse представляет подписку на вебхуки в ответах API.
+//
+// Поле Secret заполняется только при создании подписки.
+type WebhookResponse struct {
+	ID         uint      `json:"id"`
+	Wallet     string    `json:"wallet"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewWebhookResponse преобразует модель подписки в ответ API
+func NewWebhookResponse(sub *models.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:         sub.ID,
+		Wallet:     sub.WalletAddress,
+		URL:        sub.URL,
+		EventTypes: sub.EventTypes,
+		CreatedAt:  sub.CreatedAt,
+	}
+}
+
+// WebhookEventResponse представляет событие вебхука вместе с попытками доставки.
+//
+// Используется в API `GET /api/webhooks/{id}/events`.
+type WebhookEventResponse struct {
+	ID            uint64                    `json:"id"`
+	EventType     string                    `json:"event_type"`
+	Status        string                    `json:"status"`
+	Attempts      int                       `json:"attempts"`
+	NextAttemptAt *time.Time                `json:"next_attempt_at,omitempty"`
+	LastError     string                    `json:"last_error,omitempty"`
+	Payload       json.RawMessage           `json:"payload"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	DeliveredAt   *time.Time                `json:"delivered_at,omitempty"`
+	Deliveries    []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+// WebhookDeliveryResponse представляет попытку доставки события вебхука.
+//
+// Используется в API `GET /api/webhooks/{id}/events` и `POST /api/webhooks/events/{id}/redeliver`.
+type WebhookDeliveryResponse struct {
+	ID         uint64    `json:"id"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Manual     bool      `json:"manual"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewWebhookEventResponse преобразует модель события вебхука в ответ API
+func NewWebhookEventResponse(event *models.WebhookEvent) WebhookEventResponse {
+	resp := WebhookEventResponse{
+		ID:          event.ID,
+		EventType:   event.EventType,
+		Status:      event.Status,
+		Attempts:    event.Attempts,
+		LastError:   event.LastError,
+		Payload:     event.Payload,
+		CreatedAt:   event.CreatedAt,
+		DeliveredAt: event.DeliveredAt,
+		Deliveries:  make([]WebhookDeliveryResponse, len(event.Deliveries)),
+	}
+	if event.Status == models.WebhookStatusPending {
+		resp.NextAttemptAt = &event.NextAttemptAt
+	}
+	for i := range event.Deliveries {
+		resp.Deliveries[i] = NewWebhookDeliveryResponse(&event.Deliveries[i])
+	}
+	return resp
+}
+
+// NewWebhookDeliveryResponse преобразует модель попытки доставки в ответ API
+func NewWebhookDeliveryResponse(delivery *models.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:         delivery.ID,
+		StatusCode: delivery.StatusCode,
+		Error:      delivery.Error,
+		DurationMs: delivery.Duration.Milliseconds(),
+		Manual:     delivery.Manual,
+		CreatedAt:  delivery.CreatedAt,
+	}
+}
@@ -0,0 +1,48 @@
+// Package models содержит описание структур базы данных для пополнений и выводов средств
+package models
+
+import "time"
+
+// Виды платежей
+const (
+	PaymentDeposit    = "deposit"    // Пополнение кошелька из внешнего источника
+	PaymentWithdrawal = "withdrawal" // Вывод средств с кошелька во внешний источник
+)
+
+// Состояния платежа
+const (
+	PaymentPending   = "pending"   // Ожидает подтверждения платёжного провайдера
+	PaymentCompleted = "completed" // Подтверждён провайдером
+	PaymentFailed    = "failed"    // Отклонён провайдером или не может быть проведён
+)
+
+// Payment представляет пополнение или вывод средств через платёжного провайдера
+//
+// Средства поступают в систему и покидают её через системный кошелёк казначейства (TreasuryWalletAddress):
+// пополнение зачисляется после подтверждения провайдером, а при выводе средства списываются сразу
+// и возвращаются транзакцией TransactionRefund, если провайдер отклонил вывод
+//
+// Поля:
+//   - ID (uint) — уникальный идентификатор платежа (первичный ключ)
+//   - Kind (string) — вид платежа: PaymentDeposit или PaymentWithdrawal
+//   - WalletAddress (string) — адрес кошелька клиента
+//   - Amount (int64) — сумма в копейках
+//   - Status (string) — состояние: PaymentPending, PaymentCompleted или PaymentFailed
+//   - ExternalID (string) — идентификатор платежа у провайдера, уникален; по нему провайдер подтверждает платёж
+//   - TransactionID (*uint) — транзакция пополнения или вывода; nil, пока пополнение не зачислено
+//   - RefundTransactionID (*uint) — транзакция возврата средств отклонённого вывода
+//   - FailureCode (string) — причина отказа от провайдера или код ошибки проводки
+//   - CreatedAt, UpdatedAt (time.Time) — время создания и последнего изменения
+type Payment struct {
+	ID                  uint      `gorm:"primaryKey"`                                // Уникальный идентификатор платежа
+	Kind                string    `gorm:"size:16;not null"`                          // Вид платежа
+	WalletAddress       string    `gorm:"size:64;not null;index:idx_payment_wallet"` // Адрес кошелька
+	Amount              int64     `gorm:"not null"`                                  // Сумма
+	Status              string    `gorm:"size:16;not null"`                          // Состояние
+	ExternalID          string    `gorm:"size:128;not null;uniqueIndex"`             // Идентификатор у провайдера
+	TransactionID       *uint     `gorm:"index:idx_payment_transaction"`             // Транзакция пополнения или вывода
+	RefundTransactionID *uint     `gorm:"index:idx_payment_refund"`                  // Транзакция возврата
+	FailureCode         string    `gorm:"size:64"`                                   // Причина отказа
+	CreatedAt           time.Time `gorm:"autoCreateTime"`                            // Время создания
+	UpdatedAt           time.Time `gorm:"autoUpdateTime"`                            // Время изменения
+}
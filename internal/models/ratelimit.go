@@ -0,0 +1,16 @@
+// Package models содержит описание структур базы данных для ограничения частоты запросов
+package models
+
+import "time"
+
+// RateLimitBucket представляет корзину токенов, общую для всех экземпляров сервиса
+//
+// Поля:
+//   - Key (string) — ключ корзины, например "client:api_key:12" или "sender:<адрес>" (первичный ключ)
+//   - Tokens (float64) — число токенов на момент UpdatedAt
+//   - UpdatedAt (time.Time) — время последнего обращения к корзине
+type RateLimitBucket struct {
+	Key       string    `gorm:"primaryKey;size:255"`                      // Ключ корзины
+	Tokens    float64   `gorm:"not null"`                                 // Число токенов
+	UpdatedAt time.Time `gorm:"not null;index:idx_rate_limit_updated_at"` // Время последнего обращения
+}
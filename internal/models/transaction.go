@@ -11,11 +11,58 @@ import "time"
 //   - To (string) — адрес кошелька получателя (индексирован для быстрого поиска)
 //   - Amount (int64) — сумма перевода в минимальных единицах валюты (копейки)
 //   - CreatedAt (time.Time) — время создания транзакции (автоматически проставляется GORM)
+//   - Type (string) — тип транзакции: TransactionTransfer, TransactionAdjustment, TransactionDeposit,
+//     TransactionWithdrawal или TransactionRefund
+//   - ReasonCode (string) — код причины ручной корректировки (AdjustmentReasonCodes); пусто для переводов
+//   - Note (string) — комментарий администратора к ручной корректировке
+//   - Status (string) — состояние транзакции: TransactionPending, TransactionCompleted, TransactionFailed
+//     или TransactionReversed
+//   - ErrorCode (string) — код ошибки отклонённой попытки перевода (services.ErrorCode); пусто для остальных
+//   - Memo (string) — произвольный комментарий отправителя к переводу
+//   - Reference (string) — внешний идентификатор перевода в системе отправителя, уникален для кошелька отправителя
+//     среди неотклонённых транзакций (частичный уникальный индекс создаётся миграцией storage)
+//   - Metadata (map[string]string) — произвольные пары ключ-значение отправителя, хранятся в JSON
+//
+// Отклонённые попытки (TransactionFailed) хранятся для разбора обращений и не изменяют балансы
 
 type Transaction struct {
-	ID        uint      `gorm:"primary_key"`                // Уникальный идентификатор транзакции
-	From      string    `gorm:"index:idx_transaction_from"` // Адрес кошелька отправителя
-	To        string    `gorm:"index:idx_transaction_to"`   // Адрес кошелька получателя
-	Amount    int64     `gorm:"not null"`                   // Сумма перевода
-	CreatedAt time.Time `gorm:"autoCreateTime"`             // Дата и время создания транзакции
+	ID         uint              `gorm:"primary_key"`                               // Уникальный идентификатор транзакции
+	From       string            `gorm:"index:idx_transaction_from"`                // Адрес кошелька отправителя
+	To         string            `gorm:"index:idx_transaction_to"`                  // Адрес кошелька получателя
+	Amount     int64             `gorm:"not null"`                                  // Сумма перевода
+	CreatedAt  time.Time         `gorm:"autoCreateTime"`                            // Дата и время создания транзакции
+	Type       string            `gorm:"size:16;not null;default:transfer"`         // Тип транзакции
+	ReasonCode string            `gorm:"size:32" json:"ReasonCode,omitempty"`       // Код причины корректировки
+	Note       string            `gorm:"size:500" json:"Note,omitempty"`            // Комментарий к корректировке
+	Status     string            `gorm:"size:16;not null;default:completed"`        // Состояние транзакции
+	ErrorCode  string            `gorm:"size:32" json:"ErrorCode,omitempty"`        // Код ошибки отклонённой попытки
+	Memo       string            `gorm:"size:500" json:"Memo,omitempty"`            // Комментарий отправителя
+	Reference  string            `gorm:"size:128" json:"Reference,omitempty"`       // Внешний идентификатор перевода
+	Metadata   map[string]string `gorm:"serializer:json" json:"Metadata,omitempty"` // Метаданные отправителя
+}
+
+// Типы транзакций
+const (
+	TransactionTransfer   = "transfer"   // Перевод между кошельками
+	TransactionAdjustment = "adjustment" // Ручная корректировка баланса через системный кошелёк капитала
+	TransactionDeposit    = "deposit"    // Пополнение с системного кошелька казначейства
+	TransactionWithdrawal = "withdrawal" // Вывод средств на системный кошелёк казначейства
+	TransactionRefund     = "refund"     // Возврат средств неудавшегося вывода с кошелька казначейства
+)
+
+// Состояния транзакций
+const (
+	TransactionPending   = "pending"   // Средства списаны, ожидается результат внешней операции (вывод средств)
+	TransactionCompleted = "completed" // Транзакция проведена
+	TransactionFailed    = "failed"    // Попытка перевода отклонена, балансы не изменились
+	TransactionReversed  = "reversed"  // Транзакция проведена и отменена обратной транзакцией (возврат вывода)
+)
+
+// AdjustmentReasonCodes - допустимые коды причин ручной корректировки баланса
+var AdjustmentReasonCodes = []string{
+	"goodwill",         // Компенсация клиенту
+	"error_correction", // Исправление ошибки
+	"chargeback",       // Возврат платежа
+	"fee_refund",       // Возврат комиссии
+	"other",            // Прочее, подробности в комментарии
 }
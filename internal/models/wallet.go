@@ -2,9 +2,11 @@
 package models
 
 import (
+	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/hex"
 	"github.com/google/uuid"
+	"strconv"
 )
 
 // Wallet представляет модель кошелька.
@@ -12,17 +14,61 @@ import (
 // Поля:
 //   - Address (string) — уникальный адрес кошелька (первичный ключ, индексирован)
 //   - Balance (int64) — баланс кошелька в минимальных единицах валюты (копейки)
+//   - PublicKey (string) — публичный ключ Ed25519 в hex; если задан, переводы с кошелька должны быть подписаны
+//   - Nonce (uint64) — nonce последнего подписанного перевода, защищает от повторного использования подписей
+//   - Frozen (bool) — кошелёк заморожен администратором: переводы с него и на него запрещены
+//   - System (bool) — системный кошелёк (например, EquityWalletAddress): не участвует в переводах клиентов,
+//     его баланс может быть отрицательным
 type Wallet struct {
-	Address string `gorm:"primaryKey;size:64;index:idx_wallet_address"` // Уникальный адрес кошелька
-	Balance int64  `gorm:"not null"`                                    // Баланс кошелька
+	Address   string `gorm:"primaryKey;size:64;index:idx_wallet_address"` // Уникальный адрес кошелька
+	Balance   int64  `gorm:"not null"`                                    // Баланс кошелька
+	PublicKey string `gorm:"size:64"`                                     // Публичный ключ Ed25519
+	Nonce     uint64 `gorm:"not null;default:0"`                          // Nonce последнего подписанного перевода
+	Frozen    bool   `gorm:"not null;default:false"`                      // Кошелёк заморожен
+	System    bool   `gorm:"not null;default:false"`                      // Системный кошелёк
 }
 
+// EquityWalletAddress - адрес системного кошелька капитала
+//
+// Ручные корректировки проводятся как транзакции между кошельком капитала и кошельком клиента, поэтому
+// баланс кошелька капитала равен сумме всех корректировок с обратным знаком
+const EquityWalletAddress = "system:equity"
+
+// TreasuryWalletAddress - адрес системного кошелька казначейства, представляющего средства за пределами системы
+//
+// Пополнения проводятся с кошелька казначейства, выводы - на него, поэтому его баланс равен сумме выведенных
+// средств за вычетом внесённых
+const TreasuryWalletAddress = "system:treasury"
+
 // CreateWalletAddress генерирует новый уникальный адрес кошелька
 // и присваивает его полю Address
 func (w *Wallet) CreateWalletAddress() {
 	w.Address = generateWalletAddress()
 }
 
+// CreateWalletAddressFromKey присваивает полю Address адрес, производный от публичного ключа Ed25519,
+// и сохраняет ключ в поле PublicKey
+//
+// Адрес - SHA-256 от байтов ключа в hex, поэтому его формат совпадает со случайными адресами
+func (w *Wallet) CreateWalletAddressFromKey(pub ed25519.PublicKey) {
+	w.Address = DeriveWalletAddress(pub)
+	w.PublicKey = hex.EncodeToString(pub)
+}
+
+// DeriveWalletAddress возвращает адрес кошелька, производный от публичного ключа Ed25519
+func DeriveWalletAddress(pub ed25519.PublicKey) string {
+	hash := sha256.Sum256(pub)
+	return hex.EncodeToString(hash[:])
+}
+
+// SeededWalletAddress возвращает адрес кошелька, детерминированно выведенный из строки seed и номера кошелька
+//
+// Адрес - SHA-256 от "<seed>:<index>" в hex; используется начальным заполнением для воспроизводимых адресов
+func SeededWalletAddress(seed string, index int) string {
+	hash := sha256.Sum256([]byte(seed + ":" + strconv.Itoa(index)))
+	return hex.EncodeToString(hash[:])
+}
+
 // generateWalletAddress создаёт уникальный идентификатор для кошелька
 //
 // Используется UUID v4, который затем хэшируется с помощью SHA-256
@@ -0,0 +1,101 @@
+// Package models содержит описание структур базы данных для исходящих вебхуков
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Типы событий вебхуков
+const (
+	WebhookEventTransferIncoming = "transfer.incoming" // Поступление средств на кошелёк
+	WebhookEventTransferOutgoing = "transfer.outgoing" // Списание средств с кошелька
+)
+
+// Состояния доставки события вебхука
+const (
+	WebhookStatusPending   = "pending"   // Ожидает доставки или повторной попытки
+	WebhookStatusDelivered = "delivered" // Получатель ответил кодом 2xx
+	WebhookStatusFailed    = "failed"    // Попытки исчерпаны или подписка удалена
+)
+
+// WebhookSubscription представляет подписку на события кошелька
+//
+// Поля:
+//   - ID (uint) — уникальный идентификатор подписки (первичный ключ)
+//   - WalletAddress (string) — адрес кошелька, события которого отправляются
+//   - URL (string) — адрес получателя (http или https)
+//   - EventTypes ([]string) — типы событий, например "transfer.incoming"
+//   - Secret (string) — секрет для подписи HMAC-SHA256 тела запроса
+//   - CreatedAt (time.Time) — время создания подписки
+//   - DeletedAt (*time.Time) — время удаления подписки; nil, если подписка действует
+type WebhookSubscription struct {
+	ID            uint       `gorm:"primaryKey"`                                // Уникальный идентификатор подписки
+	WalletAddress string     `gorm:"size:64;not null;index:idx_webhook_wallet"` // Адрес кошелька
+	URL           string     `gorm:"size:2048;not null"`                        // Адрес получателя
+	EventTypes    []string   `gorm:"serializer:json;not null"`                  // Типы событий
+	Secret        string     `gorm:"size:128;not null"`                         // Секрет подписи
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`                            // Время создания
+	DeletedAt     *time.Time `gorm:"index:idx_webhook_deleted_at"`              // Время удаления
+}
+
+// WebhookEvent представляет событие в transactional outbox, ожидающее доставки по подписке
+//
+// Событие создаётся в той же транзакции, что и перевод, поэтому оно не теряется и не появляется
+// для отменённых переводов; доставку выполняет фоновая задача
+//
+// Поля:
+//   - ID (uint64) — уникальный идентификатор события; передаётся получателю для дедупликации
+//   - SubscriptionID (uint) — идентификатор подписки
+//   - EventType (string) — тип события
+//   - Payload (json.RawMessage) — тело запроса
+//   - Status (string) — состояние доставки: pending, delivered, failed
+//   - Attempts (int) — число выполненных автоматических попыток
+//   - NextAttemptAt (time.Time) — время следующей попытки
+//   - LastError (string) — результат последней неудачной попытки
+//   - CreatedAt (time.Time) — время создания события
+//   - DeliveredAt (*time.Time) — время успешной доставки
+//   - Deliveries ([]WebhookDelivery) — журнал попыток доставки
+type WebhookEvent struct {
+	ID             uint64            `gorm:"primaryKey"`                                              // Уникальный идентификатор события
+	SubscriptionID uint              `gorm:"not null;index:idx_webhook_event_subscription"`           // Идентификатор подписки
+	EventType      string            `gorm:"size:64;not null"`                                        // Тип события
+	Payload        json.RawMessage   `gorm:"type:jsonb;not null"`                                     // Тело запроса
+	Status         string            `gorm:"size:16;not null;index:idx_webhook_event_due,priority:1"` // Состояние доставки
+	Attempts       int               `gorm:"not null;default:0"`                                      // Число попыток
+	NextAttemptAt  time.Time         `gorm:"not null;index:idx_webhook_event_due,priority:2"`         // Время следующей попытки
+	LastError      string            `gorm:"size:1024"`                                               // Последняя ошибка
+	CreatedAt      time.Time         `gorm:"autoCreateTime"`                                          // Время создания
+	DeliveredAt    *time.Time        // Время доставки
+	Deliveries     []WebhookDelivery `gorm:"foreignKey:EventID"` // Попытки доставки
+}
+
+// WebhookDelivery представляет одну попытку доставки события
+//
+// Поля:
+//   - ID (uint64) — уникальный идентификатор попытки (первичный ключ)
+//   - EventID (uint64) — идентификатор события
+//   - StatusCode (int) — HTTP-статус ответа получателя; 0, если ответ не получен
+//   - Error (string) — описание ошибки; пусто при успешной доставке
+//   - Duration (time.Duration) — время выполнения запроса
+//   - Manual (bool) — попытка запущена вручную через API
+//   - CreatedAt (time.Time) — время попытки
+type WebhookDelivery struct {
+	ID         uint64        `gorm:"primaryKey"`                                // Уникальный идентификатор попытки
+	EventID    uint64        `gorm:"not null;index:idx_webhook_delivery_event"` // Идентификатор события
+	StatusCode int           `gorm:"not null;default:0"`                        // HTTP-статус ответа
+	Error      string        `gorm:"size:1024"`                                 // Ошибка
+	Duration   time.Duration `gorm:"not null"`                                  // Время выполнения запроса
+	Manual     bool          `gorm:"not null;default:false"`                    // Ручная попытка
+	CreatedAt  time.Time     `gorm:"autoCreateTime"`                            // Время попытки
+}
+
+// Subscribes проверяет, подписана ли подписка на тип события
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+// Package openapi содержит OpenAPI 3 описание HTTP API и его публикацию
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// spec - описание API в формате YAML
+//
+//go:embed openapi.yaml
+var spec []byte
+
+// Load разбирает и проверяет описание API
+//
+// Возвращает:
+//   - *openapi3.T: описание API
+//   - routers.Router: маршрутизатор для поиска операции по HTTP-запросу
+//   - error: ошибку разбора или проверки описания
+func Load() (*openapi3.T, routers.Router, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		return nil, nil, err
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, router, nil
+}
+
+// Handler отдаёт описание API в формате JSON
+//
+// GET /api/openapi.json
+func Handler(doc *openapi3.T) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// swaggerUI - страница Swagger UI, загружающая описание API с /api/openapi.json
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Wallet API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/api/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>`
+
+// SwaggerUI отдаёт страницу Swagger UI для описания API
+//
+// GET /api/docs
+func SwaggerUI() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUI))
+	}
+}
@@ -0,0 +1,70 @@
+// Package ratelimit реализует ограничение частоты запросов по алгоритму token bucket
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// bucket - состояние одной корзины токенов
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryStore хранит корзины в памяти процесса
+//
+// Подходит для одного экземпляра сервиса; при нескольких экземплярах каждый ведёт свои счётчики
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewMemoryStore создаёт хранилище корзин в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take пытается списать один токен из корзины с ключом key
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	var res Result
+	b.tokens, res = take(b.tokens, now.Sub(b.last), limit)
+	b.last = now
+	return res, nil
+}
+
+// Cleanup периодически удаляет корзины, к которым не обращались дольше idle
+//
+// Предназначена для запуска фоновой задачей; завершается после отмены ctx
+func (s *MemoryStore) Cleanup(ctx context.Context, interval, idle time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			threshold := s.now().Add(-idle)
+			for key, b := range s.buckets {
+				if b.last.Before(threshold) {
+					delete(s.buckets, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package ratelimit реализует ограничение частоты запросов по алгоритму token bucket
+package ratelimit
+
+import (
+	"context"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// PostgresStore хранит корзины в таблице PostgreSQL, общей для всех экземпляров сервиса
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore создаёт хранилище корзин в PostgreSQL
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Take пытается списать один токен из корзины с ключом key
+//
+// Логика работы:
+//  1. Создание полной корзины, если её ещё нет (`ON CONFLICT DO NOTHING`)
+//  2. Блокирование строки корзины `FOR UPDATE` и чтение текущего времени базы данных,
+//     чтобы часы разных экземпляров не влияли на расчёт
+//  3. Пополнение корзины за прошедшее время, списание токена и сохранение состояния
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	var res Result
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.RateLimitBucket{Key: key, Tokens: float64(limit.Burst), UpdatedAt: time.Now()}).
+			Error; err != nil {
+			return err
+		}
+
+		var row struct {
+			Tokens    float64
+			UpdatedAt time.Time
+			Now       time.Time
+		}
+		if err := tx.Raw(`SELECT tokens, updated_at, now() AS now FROM rate_limit_buckets WHERE key = ? FOR UPDATE`, key).
+			Scan(&row).Error; err != nil {
+			return err
+		}
+
+		var tokens float64
+		tokens, res = take(row.Tokens, row.Now.Sub(row.UpdatedAt), limit)
+		return tx.Model(&models.RateLimitBucket{Key: key}).
+			Updates(map[string]any{"tokens": tokens, "updated_at": row.Now}).
+			Error
+	})
+	return res, err
+}
+
+// Cleanup периодически удаляет корзины, к которым не обращались дольше idle
+//
+// Предназначена для запуска фоновой задачей; завершается после отмены ctx
+func (s *PostgresStore) Cleanup(ctx context.Context, interval, idle time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.db.WithContext(ctx).
+				Where("updated_at < now() - make_interval(secs => ?)", idle.Seconds()).
+				Delete(&models.RateLimitBucket{}).Error
+			if err != nil && ctx.Err() == nil {
+				zap.L().Warn("Rate limit cleanup failed", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Package ratelimit реализует ограничение частоты запросов по алгоритму token bucket
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit задаёт параметры корзины токенов
+//
+// Поля:
+//   - Rate (float64) — скорость пополнения корзины, токенов в секунду
+//   - Burst (int) — ёмкость корзины, то есть максимальное число запросов подряд
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result содержит решение по запросу
+//
+// Поля:
+//   - Allowed (bool) — запрос разрешён, токен списан
+//   - RetryAfter (time.Duration) — через сколько появится следующий токен, если запрос отклонён
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// Store хранит состояние корзин токенов
+type Store interface {
+	// Take пытается списать один токен из корзины с ключом key
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Cleanup периодически удаляет корзины, к которым не обращались дольше idle, до отмены ctx
+	Cleanup(ctx context.Context, interval, idle time.Duration)
+}
+
+// take пересчитывает корзину с учётом прошедшего времени и пытается списать токен
+//
+// Параметры:
+//   - tokens (float64): число токенов в корзине на момент last
+//   - elapsed (time.Duration): время, прошедшее с момента last
+//   - limit (Limit): параметры корзины
+//
+// Возвращает:
+//   - float64: число токенов после пополнения и списания
+//   - Result: решение по запросу
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, Result) {
+	if elapsed > 0 {
+		tokens = math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+	}
+
+	if tokens >= 1 {
+		return tokens - 1, Result{Allowed: true}
+	}
+
+	wait := time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+	return tokens, Result{Allowed: false, RetryAfter: wait}
+}
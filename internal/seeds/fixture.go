@@ -0,0 +1,175 @@
+package seeds
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"github.com/normalniydada/test_task_infotecs/internal/models/dto"
+	"gopkg.in/yaml.v3"
+	"os"
+	"time"
+)
+
+// Fixture описывает файл начального заполнения: кошельки с начальными балансами и исторические транзакции
+//
+// Файл записывается в YAML или JSON (JSON - подмножество YAML), суммы - в у.е. строкой или числом:
+//
+//	wallets:
+//	  - name: alice
+//	    balance: "100.00"
+//	  - name: bob
+//	    address: e240d825d255af751f5f55af8d9671beabdf2236c0a3b4e2639b3e182d994c88
+//	    balance: "50"
+//	transactions:
+//	  - from: alice
+//	    to: bob
+//	    amount: "12.50"
+//	    created_at: 2026-01-15T10:00:00Z
+type Fixture struct {
+	Wallets      []FixtureWallet      `yaml:"wallets"`
+	Transactions []FixtureTransaction `yaml:"transactions"`
+}
+
+// FixtureWallet - кошелёк файла начального заполнения
+//
+// Поля:
+//   - Name (string) — имя для ссылок из транзакций файла; в базе данных не хранится
+//   - Address (string) — адрес кошелька; если пусто, выводится из PublicKey, seed.seed или генерируется случайно
+//   - PublicKey (string) — публичный ключ Ed25519 в hex; адрес кошелька должен быть производным от него
+//   - Balance (string) — начальный баланс в у.е. до исторических транзакций
+type FixtureWallet struct {
+	Name      string `yaml:"name"`
+	Address   string `yaml:"address"`
+	PublicKey string `yaml:"public_key"`
+	Balance   string `yaml:"balance"`
+}
+
+// FixtureTransaction - историческая транзакция файла начального заполнения
+//
+// Поля:
+//   - From, To (string) — имя кошелька файла или адрес кошелька
+//   - Amount (string) — сумма перевода в у.е.
+//   - CreatedAt (time.Time) — время транзакции в RFC 3339; если не задано, время загрузки
+type FixtureTransaction struct {
+	From      string    `yaml:"from"`
+	To        string    `yaml:"to"`
+	Amount    string    `yaml:"amount"`
+	CreatedAt time.Time `yaml:"created_at"`
+}
+
+// LoadFixture читает и разбирает файл начального заполнения
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	if err := yaml.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("parse fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// build проверяет файл и рассчитывает кошельки с итоговыми балансами и транзакции для записи в базу данных
+//
+// Параметры:
+//   - seed (string): строка для детерминированных адресов кошельков без адреса и ключа; пусто - случайные адреса
+//
+// Возвращает:
+//   - []models.Wallet: кошельки с итоговыми балансами
+//   - []int64: начальные балансы кошельков (для записей аудита wallet.create, по которым выполняется сверка)
+//   - []models.Transaction: транзакции в порядке файла
+//   - error: ошибка в описании кошелька или транзакции; транзакция не может сделать баланс отрицательным
+func (f *Fixture) build(seed string) ([]models.Wallet, []int64, []models.Transaction, error) {
+	if len(f.Wallets) == 0 {
+		return nil, nil, nil, errors.New("fixture has no wallets")
+	}
+
+	wallets := make([]models.Wallet, len(f.Wallets))
+	opening := make([]int64, len(f.Wallets))
+	index := make(map[string]int, 2*len(f.Wallets)) // имя или адрес -> номер кошелька
+	for i, w := range f.Wallets {
+		balance, err := dto.ParseAmount(w.Balance)
+		if err != nil || balance < 0 {
+			return nil, nil, nil, fmt.Errorf("wallet %d: invalid balance %q", i, w.Balance)
+		}
+		wallet := models.Wallet{Address: w.Address, Balance: int64(balance)}
+
+		if w.PublicKey != "" {
+			key, err := hex.DecodeString(w.PublicKey)
+			if err != nil || len(key) != ed25519.PublicKeySize {
+				return nil, nil, nil, fmt.Errorf("wallet %d: invalid public key", i)
+			}
+			derived := models.DeriveWalletAddress(key)
+			if wallet.Address != "" && wallet.Address != derived {
+				return nil, nil, nil, fmt.Errorf("wallet %d: address does not match public key", i)
+			}
+			wallet.CreateWalletAddressFromKey(key)
+		}
+		switch {
+		case wallet.Address != "":
+		case seed != "":
+			wallet.Address = models.SeededWalletAddress(seed, i)
+		default:
+			wallet.CreateWalletAddress()
+		}
+		if len(wallet.Address) > 64 {
+			return nil, nil, nil, fmt.Errorf("wallet %d: address longer than 64 characters", i)
+		}
+
+		if _, ok := index[wallet.Address]; ok {
+			return nil, nil, nil, fmt.Errorf("wallet %d: duplicate address %s", i, wallet.Address)
+		}
+		index[wallet.Address] = i
+		if w.Name != "" {
+			if _, ok := index[w.Name]; ok {
+				return nil, nil, nil, fmt.Errorf("wallet %d: duplicate name %q", i, w.Name)
+			}
+			index[w.Name] = i
+		}
+		wallets[i] = wallet
+		opening[i] = wallet.Balance
+	}
+
+	now := time.Now()
+	transactions := make([]models.Transaction, len(f.Transactions))
+	for i, t := range f.Transactions {
+		from, ok := index[t.From]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("transaction %d: unknown sender %q", i, t.From)
+		}
+		to, ok := index[t.To]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("transaction %d: unknown receiver %q", i, t.To)
+		}
+		if from == to {
+			return nil, nil, nil, fmt.Errorf("transaction %d: sender and receiver are the same wallet", i)
+		}
+		amount, err := dto.ParseAmount(t.Amount)
+		if err != nil || amount <= 0 {
+			return nil, nil, nil, fmt.Errorf("transaction %d: invalid amount %q", i, t.Amount)
+		}
+		if wallets[from].Balance < int64(amount) {
+			return nil, nil, nil, fmt.Errorf("transaction %d: not enough money on %s", i, wallets[from].Address)
+		}
+
+		wallets[from].Balance -= int64(amount)
+		wallets[to].Balance += int64(amount)
+		createdAt := t.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		transactions[i] = models.Transaction{
+			From:      wallets[from].Address,
+			To:        wallets[to].Address,
+			Amount:    int64(amount),
+			CreatedAt: createdAt,
+			Type:      models.TransactionTransfer,
+			Status:    models.TransactionCompleted,
+		}
+	}
+	return wallets, opening, transactions, nil
+}
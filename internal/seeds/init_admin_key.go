@@ -0,0 +1,57 @@
+// Package seeds содержит функции для инициализации начальных данных в базе данных
+package seeds
+
+import (
+	"errors"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+// InitAdminKey создаёт административный ключ доступа из конфигурации, если его ещё нет в базе данных
+//
+// Параметры:
+//   - db (*gorm.DB): подключение к базе данных GORM
+//   - key (string): значение ключа; если пусто, функция ничего не делает
+//   - zLog (*zap.Logger): логгер для записи событий
+//
+// Процесс выполнения:
+//  1. Поиск ключа по его хешу (в том числе среди отозванных, чтобы не восстанавливать отозванный ключ)
+//  2. Если ключ не найден, создание административного ключа с именем "bootstrap" и записи журнала аудита
+//  3. Логирование успешного выполнения или фатальную ошибку при записи
+func InitAdminKey(db *gorm.DB, key string, zLog *zap.Logger) {
+	if key == "" {
+		return
+	}
+
+	hash := auth.HashKey(key)
+	err := db.Where("key_hash = ?", hash).First(&models.APIKey{}).Error
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		zLog.Fatal("Error init admin key: ", zap.Error(err))
+	}
+
+	apiKey := models.APIKey{
+		Name:    "bootstrap",
+		Prefix:  auth.KeyPrefix(key),
+		KeyHash: hash,
+		Admin:   true,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&apiKey).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActionAPIKeyCreate, audit.EntityAPIKey, strconv.FormatUint(uint64(apiKey.ID), 10),
+			nil, map[string]any{"name": apiKey.Name, "prefix": apiKey.Prefix, "admin": apiKey.Admin})
+	})
+	if err != nil {
+		zLog.Fatal("Error init admin key: ", zap.Error(err))
+	}
+
+	zLog.Info("Init admin key successfully", zap.String("prefix", apiKey.Prefix))
+}
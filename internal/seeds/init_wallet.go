@@ -2,45 +2,99 @@
 package seeds
 
 import (
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
 	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"github.com/normalniydada/test_task_infotecs/internal/services"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
-// InitWallets создаёт 10 тестовых кошельков с балансом 100.00 у.е. (10000 в минимальных единицах валюты)
+// InitWallets заполняет пустую базу данных кошельками по настройкам начального заполнения
 // Если в базе данных уже есть кошельки, функция ничего не делает
 //
 // Параметры:
 //   - db (*gorm.DB): подключение к базе данных GORM
+//   - cfg (*config.SeedConfig): количество и баланс кошельков, строка для адресов или файл начального заполнения
 //   - zLog (*zap.Logger): логгер для записи событий
 //
 // Процесс выполнения:
 //  1. Подсчитывание количества кошельков в базе данных
 //  2. Если кошельки уже существуют, завершается выполнение функции
-//  3. Генерация 10 новых кошельков с уникальными адресами и балансом 10000 (100.00 у.е.)
-//  4. Запись кошельков в базу данных
+//  3. Если задан cfg.Fixture, загрузка кошельков и исторических транзакций из файла (см. Fixture);
+//     иначе генерация cfg.Wallets кошельков с балансом cfg.Balance и адресами из cfg.Seed или случайными
+//  4. Запись кошельков, транзакций и записей журнала аудита в базу данных в одной транзакции.
+//     В записи аудита wallet.create сохраняется начальный баланс до исторических транзакций, поэтому сверка
+//     балансов сходится
 //  5. Логирование успешного выполнения или фатальную ошибку при записи
-func InitWallets(db *gorm.DB, zLog *zap.Logger) {
+func InitWallets(db *gorm.DB, cfg *config.SeedConfig, zLog *zap.Logger) {
 	var countWallets int64
-	db.Model(&models.Wallet{}).Count(&countWallets)
+	if err := db.Model(&models.Wallet{}).Count(&countWallets).Error; err != nil {
+		zLog.Fatal("Error init wallet: ", zap.Error(err))
+	}
 
 	// Если кошельки существуют, выход
 	if countWallets > 0 {
 		return
 	}
 
-	// Создание 10 кошельков
-	wallets := make([]models.Wallet, 10)
-	for i := 0; i < 10; i++ {
-		wallet := models.Wallet{Balance: 10000} // 100 у.е
-		wallet.CreateWalletAddress()            // Генерация уникального адреса
-		wallets[i] = wallet
+	var (
+		wallets      []models.Wallet
+		opening      []int64
+		transactions []models.Transaction
+	)
+	if cfg.Fixture != "" {
+		fixture, err := LoadFixture(cfg.Fixture)
+		if err != nil {
+			zLog.Fatal("Error init wallet: ", zap.Error(err))
+		}
+		wallets, opening, transactions, err = fixture.build(cfg.Seed)
+		if err != nil {
+			zLog.Fatal("Error init wallet: ", zap.String("fixture", cfg.Fixture), zap.Error(err))
+		}
+	} else {
+		wallets = make([]models.Wallet, cfg.Wallets)
+		opening = make([]int64, cfg.Wallets)
+		for i := range wallets {
+			wallets[i].Balance = cfg.Balance
+			if cfg.Seed != "" {
+				wallets[i].Address = models.SeededWalletAddress(cfg.Seed, i)
+			} else {
+				wallets[i].CreateWalletAddress() // Генерация уникального адреса
+			}
+			opening[i] = cfg.Balance
+		}
+	}
+	if len(wallets) == 0 {
+		return
 	}
 
-	// Запись кошельков в БД
-	if err := db.Create(&wallets).Error; err != nil {
+	// Запись кошельков, транзакций и журнала аудита в БД
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&wallets, 1000).Error; err != nil {
+			return err
+		}
+		if len(transactions) > 0 {
+			if err := tx.CreateInBatches(&transactions, 1000).Error; err != nil {
+				return err
+			}
+		}
+		for i := range wallets {
+			state := services.WalletState(&wallets[i])
+			state["balance"] = opening[i]
+			if err := audit.Record(tx, audit.ActionWalletCreate, audit.EntityWallet, wallets[i].Address,
+				nil, state); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		zLog.Fatal("Error init wallet: ", zap.Error(err))
 	}
 
-	zLog.Info("Init wallets successfully")
+	zLog.Info("Init wallets successfully",
+		zap.Int("wallets", len(wallets)),
+		zap.Int("transactions", len(transactions)),
+	)
 }
@@ -0,0 +1,221 @@
+// Package services содержит бизнес-логику для работы с ключами доступа к API
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
+	"github.com/normalniydada/test_task_infotecs/internal/auth"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// Определение возможных ошибок при работе с ключами доступа
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found") // Ошибка: ключ не найден или отозван
+	ErrInvalidAPIKey  = errors.New("invalid api key")   // Ошибка: ключ не существует или отозван
+)
+
+// CreateAPIKey создаёт новый ключ доступа к API и привязывает к нему кошельки
+//
+// Параметры:
+//   - ctx (context.Context): контекст запроса
+//   - db (*gorm.DB): подключение к базе данных
+//   - name (string): описание владельца ключа
+//   - admin (bool): выдать ли административные права
+//   - wallets ([]string): кошельки, с которых владелец ключа может списывать средства
+//
+// Возвращает:
+//   - string: сам ключ; в базе данных сохраняется только его хеш, поэтому показать ключ можно только сейчас
+//   - *models.APIKey: созданная запись ключа
+//   - error: ErrWalletNotFound, если один из кошельков не существует; другую ошибку при сбое БД
+func CreateAPIKey(ctx context.Context, db *gorm.DB, name string, admin bool, wallets []string) (_ string, _ *models.APIKey, err error) {
+	ctx, span := startSpan(ctx, "services.CreateAPIKey", attribute.Bool("api_key.admin", admin))
+	defer func() { endSpan(span, err) }()
+
+	raw, prefix := auth.GenerateKey()
+	key := models.APIKey{
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: auth.HashKey(raw),
+		Admin:   admin,
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkWalletsExist(tx, wallets); err != nil {
+			return err
+		}
+		if err := tx.Create(&key).Error; err != nil {
+			return err
+		}
+		if err := replaceKeyWallets(tx, &key, wallets); err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActionAPIKeyCreate, audit.EntityAPIKey, keyID(&key), nil, apiKeyState(&key))
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return raw, &key, nil
+}
+
+// ListAPIKeys возвращает все ключи доступа с привязанными кошельками, включая отозванные
+func ListAPIKeys(ctx context.Context, db *gorm.DB) (_ []models.APIKey, err error) {
+	ctx, span := startSpan(ctx, "services.ListAPIKeys")
+	defer func() { endSpan(span, err) }()
+
+	var keys []models.APIKey
+	if err = db.WithContext(ctx).Preload("Wallets").Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// SetAPIKeyWallets заменяет список кошельков, привязанных к действующему ключу
+//
+// Возвращает:
+//   - *models.APIKey: ключ с обновлённым списком кошельков
+//   - error: ErrAPIKeyNotFound, если ключ не найден или отозван; ErrWalletNotFound, если кошелёк не существует
+func SetAPIKeyWallets(ctx context.Context, db *gorm.DB, id uint, wallets []string) (_ *models.APIKey, err error) {
+	ctx, span := startSpan(ctx, "services.SetAPIKeyWallets", attribute.Int("api_key.id", int(id)))
+	defer func() { endSpan(span, err) }()
+
+	var key models.APIKey
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND revoked_at IS NULL", id).First(&key).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAPIKeyNotFound
+			}
+			return err
+		}
+		if err := tx.Where("api_key_id = ?", key.ID).Find(&key.Wallets).Error; err != nil {
+			return err
+		}
+		before := map[string]any{"wallets": key.Addresses()}
+
+		if err := checkWalletsExist(tx, wallets); err != nil {
+			return err
+		}
+		if err := replaceKeyWallets(tx, &key, wallets); err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActionAPIKeyWalletsSet, audit.EntityAPIKey, keyID(&key),
+			before, map[string]any{"wallets": key.Addresses()})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey отзывает ключ доступа; запись ключа сохраняется для истории
+//
+// Возвращает:
+//   - error: ErrAPIKeyNotFound, если ключ не найден или уже отозван
+func RevokeAPIKey(ctx context.Context, db *gorm.DB, id uint) (err error) {
+	ctx, span := startSpan(ctx, "services.RevokeAPIKey", attribute.Int("api_key.id", int(id)))
+	defer func() { endSpan(span, err) }()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		revokedAt := time.Now()
+		res := tx.Model(&models.APIKey{}).
+			Where("id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", revokedAt)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrAPIKeyNotFound
+		}
+		return audit.Record(tx, audit.ActionAPIKeyRevoke, audit.EntityAPIKey, strconv.FormatUint(uint64(id), 10),
+			map[string]any{"revoked_at": nil},
+			map[string]any{"revoked_at": revokedAt},
+		)
+	})
+}
+
+// AuthenticateAPIKey находит действующий ключ по его значению
+//
+// Возвращает:
+//   - *models.APIKey: ключ с привязанными кошельками
+//   - error: ErrInvalidAPIKey, если ключ не существует или отозван; другую ошибку при сбое БД
+func AuthenticateAPIKey(ctx context.Context, db *gorm.DB, raw string) (_ *models.APIKey, err error) {
+	ctx, span := startSpan(ctx, "services.AuthenticateAPIKey")
+	defer func() { endSpan(span, err) }()
+
+	var key models.APIKey
+	err = db.WithContext(ctx).Preload("Wallets").
+		Where("key_hash = ? AND revoked_at IS NULL", auth.HashKey(raw)).
+		First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// keyID возвращает идентификатор ключа для журнала аудита
+func keyID(key *models.APIKey) string {
+	return strconv.FormatUint(uint64(key.ID), 10)
+}
+
+// apiKeyState возвращает состояние ключа для журнала аудита (без хеша ключа)
+func apiKeyState(key *models.APIKey) map[string]any {
+	return map[string]any{
+		"name":    key.Name,
+		"prefix":  key.Prefix,
+		"admin":   key.Admin,
+		"wallets": key.Addresses(),
+	}
+}
+
+// checkWalletsExist проверяет, что все перечисленные кошельки существуют
+func checkWalletsExist(tx *gorm.DB, wallets []string) error {
+	if len(wallets) == 0 {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&models.Wallet{}).Where("address IN ?", wallets).Count(&count).Error; err != nil {
+		return err
+	}
+	if count != int64(len(uniqueStrings(wallets))) {
+		return ErrWalletNotFound
+	}
+	return nil
+}
+
+// replaceKeyWallets заменяет привязанные к ключу кошельки на переданный список
+func replaceKeyWallets(tx *gorm.DB, key *models.APIKey, wallets []string) error {
+	if err := tx.Where("api_key_id = ?", key.ID).Delete(&models.APIKeyWallet{}).Error; err != nil {
+		return err
+	}
+
+	key.Wallets = make([]models.APIKeyWallet, 0, len(wallets))
+	for _, address := range uniqueStrings(wallets) {
+		key.Wallets = append(key.Wallets, models.APIKeyWallet{APIKeyID: key.ID, WalletAddress: address})
+	}
+	if len(key.Wallets) == 0 {
+		return nil
+	}
+	return tx.Create(&key.Wallets).Error
+}
+
+// uniqueStrings возвращает значения без повторов, сохраняя порядок
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+// Package services содержит бизнес-логику для чтения журнала аудита
+package services
+
+import (
+	"context"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// AuditFilter задаёт условия выборки записей журнала аудита; пустые поля не ограничивают выборку
+//
+// Поля:
+//   - Actor, Action, EntityType, EntityID, RequestID (string) — точное совпадение
+//   - Since, Until (time.Time) — интервал времени записи [Since, Until)
+//   - BeforeID (uint64) — только записи с ID меньше указанного (постраничный вывод)
+//   - Limit (int) — максимальное число записей
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	RequestID  string
+	Since      time.Time
+	Until      time.Time
+	BeforeID   uint64
+	Limit      int
+}
+
+// ListAuditLogs возвращает записи журнала аудита по фильтру, начиная с самых новых
+//
+// Возвращает:
+//   - []models.AuditLog: записи, отсортированные по убыванию ID
+//   - error: ошибку при выполнении запроса
+func ListAuditLogs(ctx context.Context, db *gorm.DB, filter AuditFilter) (_ []models.AuditLog, err error) {
+	ctx, span := startSpan(ctx, "services.ListAuditLogs")
+	defer func() { endSpan(span, err) }()
+
+	query := db.WithContext(ctx).Model(&models.AuditLog{})
+	for column, value := range map[string]string{
+		"actor":       filter.Actor,
+		"action":      filter.Action,
+		"entity_type": filter.EntityType,
+		"entity_id":   filter.EntityID,
+		"request_id":  filter.RequestID,
+	} {
+		if value != "" {
+			query = query.Where(column+" = ?", value)
+		}
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	if filter.BeforeID > 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var logs []models.AuditLog
+	if err = query.Order("id desc").Limit(filter.Limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,72 @@
+// Package services содержит бизнес-логику для работы с транзакциями и кошельками
+package services
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrForbidden - ошибка: субъект запроса не имеет прав на операцию с кошельком
+var ErrForbidden = errors.New("wallet not permitted")
+
+// errorCodes сопоставляет ошибки сервисного слоя с их машиночитаемыми кодами
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrSenderNotFound, "sender_not_found"},
+	{ErrReceiverNotFound, "receiver_not_found"},
+	{ErrNotEnoughMoney, "not_enough_money"},
+	{ErrSelfTransfer, "self_transfer"},
+	{ErrInvalidAmount, "invalid_amount"},
+	{ErrTransactionNotFound, "transaction_not_found"},
+	{ErrInvalidStatusFilter, "invalid_status"},
+	{ErrWalletNotFound, "wallet_not_found"},
+	{ErrAPIKeyNotFound, "api_key_not_found"},
+	{ErrInvalidAPIKey, "invalid_api_key"},
+	{ErrForbidden, "forbidden"},
+	{ErrSignatureRequired, "signature_required"},
+	{ErrInvalidSignature, "invalid_signature"},
+	{ErrInvalidNonce, "invalid_nonce"},
+	{ErrInvalidPublicKey, "invalid_public_key"},
+	{ErrPublicKeyAlreadySet, "public_key_already_set"},
+	{ErrWalletExists, "wallet_exists"},
+	{ErrWalletFrozen, "wallet_frozen"},
+	{ErrReasonRequired, "reason_required"},
+	{ErrInvalidReasonCode, "invalid_reason_code"},
+	{ErrNoteTooLong, "note_too_long"},
+	{ErrSystemWallet, "system_wallet"},
+	{ErrMemoTooLong, "memo_too_long"},
+	{ErrInvalidReference, "invalid_reference"},
+	{ErrInvalidMetadata, "invalid_metadata"},
+	{ErrReferenceExists, "reference_exists"},
+	{ErrPaymentNotFound, "payment_not_found"},
+	{ErrPaymentExists, "payment_exists"},
+	{ErrPaymentFinalized, "payment_finalized"},
+	{ErrInvalidExternalID, "invalid_external_id"},
+	{ErrInvalidPaymentOutcome, "invalid_payment_status"},
+	{ErrWebhookNotFound, "webhook_not_found"},
+	{ErrWebhookEventNotFound, "webhook_event_not_found"},
+	{ErrInvalidWebhookURL, "invalid_webhook_url"},
+	{ErrInvalidEventType, "invalid_event_type"},
+	{context.Canceled, "canceled"},
+	{context.DeadlineExceeded, "timeout"},
+}
+
+// ErrorCode возвращает машиночитаемый код ошибки сервисного слоя
+//
+// Возвращает:
+//   - "" для nil
+//   - код известной ошибки (например, "not_enough_money")
+//   - "internal" для прочих ошибок
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	return "internal"
+}
@@ -0,0 +1,325 @@
+// Package services содержит бизнес-логику для работы с пополнениями и выводами средств
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
+	"github.com/normalniydada/test_task_infotecs/internal/events"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strconv"
+)
+
+// Определение возможных ошибок при работе с платежами
+var (
+	ErrPaymentNotFound       = errors.New("payment not found")               // Ошибка: платёж не найден
+	ErrPaymentExists         = errors.New("payment with external id exists") // Ошибка: платёж с таким внешним идентификатором уже создан
+	ErrPaymentFinalized      = errors.New("payment already finalized")       // Ошибка: платёж уже подтверждён или отклонён с другим результатом
+	ErrInvalidExternalID     = errors.New("invalid external id")             // Ошибка: внешний идентификатор пуст или длиннее maxExternalID
+	ErrInvalidPaymentOutcome = errors.New("payment status must be final")    // Ошибка: провайдер передал состояние, отличное от completed и failed
+)
+
+// maxExternalID - максимальная длина внешнего идентификатора платежа
+const maxExternalID = 128
+
+// CreateDeposit создаёт пополнение кошелька в состоянии pending
+//
+// Средства зачисляются транзакцией TransactionDeposit с кошелька казначейства только после подтверждения
+// провайдером (см. ConfirmPayment).
+//
+// Параметры:
+//   - ctx (context.Context): контекст запроса
+//   - db (*gorm.DB): подключение к базе данных
+//   - address (string): адрес кошелька
+//   - amount (int64): сумма в копейках
+//   - externalID (string): идентификатор платежа у провайдера
+//
+// Возвращает:
+//   - *models.Payment: созданный платёж
+//   - error: ErrInvalidAmount, ErrInvalidExternalID, ErrWalletNotFound, ErrSystemWallet, ErrWalletFrozen,
+//     ErrPaymentExists или ошибку БД
+func CreateDeposit(ctx context.Context, db *gorm.DB, address string, amount int64, externalID string) (_ *models.Payment, err error) {
+	ctx, span := startSpan(ctx, "services.CreateDeposit",
+		attribute.String("wallet.address", address),
+		attribute.Int64("payment.amount", amount),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err = validatePayment(amount, externalID); err != nil {
+		return nil, err
+	}
+
+	payment := models.Payment{
+		Kind:          models.PaymentDeposit,
+		WalletAddress: address,
+		Amount:        amount,
+		Status:        models.PaymentPending,
+		ExternalID:    externalID,
+	}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureSystemWallet(tx, models.TreasuryWalletAddress); err != nil {
+			return err
+		}
+
+		var wallet models.Wallet
+		if err := tx.Where("address = ?", address).First(&wallet).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+		if wallet.System {
+			return ErrSystemWallet
+		}
+		if wallet.Frozen {
+			return ErrWalletFrozen
+		}
+
+		return createPayment(tx, &payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// CreateWithdrawal создаёт вывод средств с кошелька в состоянии pending
+//
+// Средства сразу списываются транзакцией TransactionWithdrawal в состоянии TransactionPending на кошелёк
+// казначейства, чтобы их нельзя было потратить до ответа провайдера; если провайдер отклонит вывод,
+// они возвращаются (см. ConfirmPayment).
+//
+// Если у кошелька зарегистрирован ключ Ed25519, вывод должен быть подписан: sig - подпись сообщения
+// WithdrawalMessage, nonce которой больше nonce последнего подписанного списания с кошелька.
+//
+// Параметры и возможные ошибки те же, что и у CreateDeposit, а также ErrNotEnoughMoney и
+// ErrSignatureRequired, ErrInvalidNonce, ErrInvalidSignature
+func CreateWithdrawal(ctx context.Context, db *gorm.DB, address string, amount int64, externalID string, sig *Signature) (_ *models.Payment, err error) {
+	ctx, span := startSpan(ctx, "services.CreateWithdrawal",
+		attribute.String("wallet.address", address),
+		attribute.Int64("payment.amount", amount),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err = validatePayment(amount, externalID); err != nil {
+		return nil, err
+	}
+
+	payment := models.Payment{
+		Kind:          models.PaymentWithdrawal,
+		WalletAddress: address,
+		Amount:        amount,
+		Status:        models.PaymentPending,
+		ExternalID:    externalID,
+	}
+	var event events.Transfer
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureSystemWallet(tx, models.TreasuryWalletAddress); err != nil {
+			return err
+		}
+		if err := createPayment(tx, &payment); err != nil {
+			return err
+		}
+
+		var err error
+		event, err = applyTransaction(tx, models.Transaction{
+			From:   address,
+			To:     models.TreasuryWalletAddress,
+			Amount: amount,
+			Type:   models.TransactionWithdrawal,
+			Status: models.TransactionPending,
+		}, signDebit(sig, func(nonce uint64) []byte { return WithdrawalMessage(address, amount, externalID, nonce) }))
+		if errors.Is(err, ErrSenderNotFound) {
+			return ErrWalletNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		payment.TransactionID = &event.Transaction.ID
+		return tx.Model(&payment).Update("transaction_id", payment.TransactionID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events.Publish(event)
+	return &payment, nil
+}
+
+// ConfirmPayment применяет ответ платёжного провайдера к платежу в состоянии pending
+//
+// Параметры:
+//   - ctx (context.Context): контекст запроса
+//   - db (*gorm.DB): подключение к базе данных
+//   - externalID (string): идентификатор платежа у провайдера
+//   - status (string): итоговое состояние models.PaymentCompleted или models.PaymentFailed
+//   - failureCode (string): причина отказа от провайдера (для failed)
+//
+// Возвращает:
+//   - *models.Payment: платёж после изменения
+//   - error: ErrInvalidPaymentOutcome, ErrPaymentNotFound, ErrPaymentFinalized или ошибку БД
+//
+// Логика работы:
+//  1. Блокирование платежа FOR UPDATE; повторное подтверждение с тем же результатом возвращает платёж без изменений
+//  2. Пополнение completed: зачисление транзакцией TransactionDeposit с кошелька казначейства. Если зачисление
+//     невозможно (например, кошелёк заморожен), платёж переходит в failed с кодом ошибки вместо failureCode
+//  3. Вывод: транзакция списания переходит в TransactionCompleted, а при failed - в TransactionReversed
+//     с возвратом списанных средств транзакцией TransactionRefund
+//  4. Изменение состояния платежа и запись журнала аудита в той же транзакции; публикация транзакций после фиксации
+func ConfirmPayment(ctx context.Context, db *gorm.DB, externalID, status, failureCode string) (_ *models.Payment, err error) {
+	ctx, span := startSpan(ctx, "services.ConfirmPayment",
+		attribute.String("payment.external_id", externalID),
+		attribute.String("payment.status", status),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if status != models.PaymentCompleted && status != models.PaymentFailed {
+		return nil, ErrInvalidPaymentOutcome
+	}
+	if status == models.PaymentCompleted {
+		failureCode = ""
+	}
+
+	var payment models.Payment
+	var published []events.Transfer
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("external_id = ?", externalID).
+			First(&payment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPaymentNotFound
+			}
+			return err
+		}
+		if payment.Status != models.PaymentPending {
+			if payment.Status == status {
+				return nil
+			}
+			return ErrPaymentFinalized
+		}
+
+		before := PaymentState(&payment)
+		payment.Status, payment.FailureCode = status, failureCode
+		switch {
+		case payment.Kind == models.PaymentDeposit && status == models.PaymentCompleted:
+			event, err := applyTransaction(tx, models.Transaction{
+				From:   models.TreasuryWalletAddress,
+				To:     payment.WalletAddress,
+				Amount: payment.Amount,
+				Type:   models.TransactionDeposit,
+			}, nil)
+			switch {
+			case err == nil:
+				payment.TransactionID = &event.Transaction.ID
+				published = append(published, event)
+			case ErrorCode(err) != "internal":
+				// Проверки выполняются до изменения данных, поэтому платёж можно отклонить в той же транзакции
+				payment.Status, payment.FailureCode = models.PaymentFailed, ErrorCode(err)
+			default:
+				return err
+			}
+		case payment.Kind == models.PaymentWithdrawal && status == models.PaymentFailed:
+			event, err := applyTransaction(tx, models.Transaction{
+				From:   models.TreasuryWalletAddress,
+				To:     payment.WalletAddress,
+				Amount: payment.Amount,
+				Type:   models.TransactionRefund,
+			}, nil)
+			if err != nil {
+				return err
+			}
+			payment.RefundTransactionID = &event.Transaction.ID
+			published = append(published, event)
+		}
+		if payment.Kind == models.PaymentWithdrawal && payment.TransactionID != nil {
+			txStatus := models.TransactionCompleted
+			if payment.Status == models.PaymentFailed {
+				txStatus = models.TransactionReversed
+			}
+			if err := tx.Model(&models.Transaction{}).
+				Where("id = ?", *payment.TransactionID).
+				Update("status", txStatus).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Save(&payment).Error; err != nil {
+			return err
+		}
+		action := audit.ActionPaymentComplete
+		if payment.Status == models.PaymentFailed {
+			action = audit.ActionPaymentFail
+		}
+		return audit.Record(tx, action, audit.EntityPayment, strconv.FormatUint(uint64(payment.ID), 10),
+			before, PaymentState(&payment))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range published {
+		events.Publish(event)
+	}
+	return &payment, nil
+}
+
+// GetPayment получает платёж по идентификатору
+//
+// Возвращает:
+//   - *models.Payment: найденный платёж
+//   - error: ErrPaymentNotFound, если платёж не найден; другую ошибку при сбое БД
+func GetPayment(ctx context.Context, db *gorm.DB, id uint) (_ *models.Payment, err error) {
+	ctx, span := startSpan(ctx, "services.GetPayment", attribute.Int64("payment.id", int64(id)))
+	defer func() { endSpan(span, err) }()
+
+	var payment models.Payment
+	if err = db.WithContext(ctx).First(&payment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// PaymentState возвращает состояние платежа для журнала аудита
+func PaymentState(payment *models.Payment) map[string]any {
+	return map[string]any{
+		"kind":                  payment.Kind,
+		"wallet":                payment.WalletAddress,
+		"amount":                payment.Amount,
+		"status":                payment.Status,
+		"external_id":           payment.ExternalID,
+		"transaction_id":        payment.TransactionID,
+		"refund_transaction_id": payment.RefundTransactionID,
+		"failure_code":          payment.FailureCode,
+	}
+}
+
+// validatePayment проверяет сумму и внешний идентификатор нового платежа
+func validatePayment(amount int64, externalID string) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if externalID == "" || len(externalID) > maxExternalID {
+		return ErrInvalidExternalID
+	}
+	return nil
+}
+
+// createPayment сохраняет новый платёж и запись журнала аудита; повторный внешний идентификатор - ErrPaymentExists
+func createPayment(tx *gorm.DB, payment *models.Payment) error {
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(payment)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrPaymentExists
+	}
+	return audit.Record(tx, audit.ActionPaymentCreate, audit.EntityPayment, strconv.FormatUint(uint64(payment.ID), 10),
+		nil, PaymentState(payment))
+}
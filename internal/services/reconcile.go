@@ -0,0 +1,161 @@
+// Package services содержит бизнес-логику для работы с кошельками и транзакциями
+package services
+
+import (
+	"context"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"gorm.io/gorm"
+	"sort"
+)
+
+// Проблемы, обнаруживаемые сверкой
+const (
+	ReconcileNegativeBalance = "negative_balance" // Отрицательный баланс кошелька
+	ReconcileNoOpening       = "no_opening"       // Нет записи аудита о создании кошелька с начальным балансом
+	ReconcileMismatch        = "balance_mismatch" // Баланс не совпадает с рассчитанным по истории
+	ReconcileUnknownWallet   = "unknown_wallet"   // Транзакции ссылаются на несуществующий кошелёк
+)
+
+// ReconcileIssue описывает расхождение, найденное сверкой
+//
+// Поля:
+//   - Address (string) — адрес кошелька
+//   - Problem (string) — вид проблемы (Reconcile*)
+//   - Balance (int64) — текущий баланс в копейках
+//   - Expected (int64) — баланс, рассчитанный по истории: начальный баланс + входящие - исходящие + корректировки
+type ReconcileIssue struct {
+	Address  string `json:"address"`
+	Problem  string `json:"problem"`
+	Balance  int64  `json:"balance"`
+	Expected int64  `json:"expected"`
+}
+
+// ReconcileReport - результат сверки балансов с историей операций
+type ReconcileReport struct {
+	Wallets      int              `json:"wallets"`       // Количество проверенных кошельков
+	Transactions int64            `json:"transactions"`  // Количество проведённых транзакций
+	TotalBalance int64            `json:"total_balance"` // Сумма балансов всех кошельков в копейках
+	Issues       []ReconcileIssue `json:"issues"`        // Найденные расхождения
+}
+
+// Reconcile сверяет балансы кошельков с историей операций
+//
+// Параметры:
+//   - ctx (context.Context): контекст выполнения
+//   - db (*gorm.DB): подключение к базе данных
+//
+// Возвращает:
+//   - *ReconcileReport: отчёт со списком расхождений (пустой, если балансы согласованы)
+//   - error: ошибку при выполнении запросов
+//
+// Логика работы:
+//  1. Начальный баланс кошелька берётся из записи аудита wallet.create
+//  2. К нему прибавляются входящие транзакции (переводы и корректировки через кошелёк капитала), вычитаются
+//     исходящие транзакции; отклонённые попытки переводов (failed) не учитываются
+//  3. Кошельки с отрицательным балансом (кроме системных), без начальной записи или с расхождением попадают в отчёт,
+//     как и адреса из транзакций, которых нет среди кошельков
+//
+// Сверка выполняется в одной транзакции REPEATABLE READ, чтобы видеть согласованный снимок данных
+func Reconcile(ctx context.Context, db *gorm.DB) (_ *ReconcileReport, err error) {
+	ctx, span := startSpan(ctx, "services.Reconcile")
+	defer func() { endSpan(span, err) }()
+
+	report := &ReconcileReport{Issues: []ReconcileIssue{}}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ").Error; err != nil {
+			return err
+		}
+
+		var wallets []models.Wallet
+		if err := tx.Select("address", "balance", "system").Order("address").Find(&wallets).Error; err != nil {
+			return err
+		}
+		// Отклонённые попытки переводов не изменяли балансы
+		ledger := func() *gorm.DB {
+			return tx.Model(&models.Transaction{}).Where("status <> ?", models.TransactionFailed)
+		}
+		if err := ledger().Count(&report.Transactions).Error; err != nil {
+			return err
+		}
+
+		type sum struct {
+			Address string
+			Amount  int64
+		}
+		collect := func(query *gorm.DB) (map[string]int64, error) {
+			var rows []sum
+			if err := query.Scan(&rows).Error; err != nil {
+				return nil, err
+			}
+			m := make(map[string]int64, len(rows))
+			for _, r := range rows {
+				m[r.Address] = r.Amount
+			}
+			return m, nil
+		}
+
+		incoming, err := collect(ledger().
+			Select(`"to" AS address, SUM(amount) AS amount`).Group(`"to"`))
+		if err != nil {
+			return err
+		}
+		outgoing, err := collect(ledger().
+			Select(`"from" AS address, SUM(amount) AS amount`).Group(`"from"`))
+		if err != nil {
+			return err
+		}
+		openings, err := collect(tx.Model(&models.AuditLog{}).
+			Select(`entity_id AS address, SUM((after->>'balance')::bigint) AS amount`).
+			Where("action = ? AND entity_type = ?", audit.ActionWalletCreate, audit.EntityWallet).
+			Group("entity_id"))
+		if err != nil {
+			return err
+		}
+
+		known := make(map[string]bool, len(wallets))
+		for _, w := range wallets {
+			known[w.Address] = true
+			report.TotalBalance += w.Balance
+
+			opening, ok := openings[w.Address]
+			expected := opening + incoming[w.Address] - outgoing[w.Address]
+			issue := ReconcileIssue{Address: w.Address, Balance: w.Balance, Expected: expected}
+			switch {
+			case w.Balance < 0 && !w.System:
+				issue.Problem = ReconcileNegativeBalance
+			case !ok:
+				issue.Problem = ReconcileNoOpening
+			case w.Balance != expected:
+				issue.Problem = ReconcileMismatch
+			default:
+				continue
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+		report.Wallets = len(wallets)
+
+		var unknown []string
+		for _, flows := range []map[string]int64{incoming, outgoing} {
+			for address := range flows {
+				if !known[address] {
+					known[address] = true
+					unknown = append(unknown, address)
+				}
+			}
+		}
+		sort.Strings(unknown)
+		for _, address := range unknown {
+			report.Issues = append(report.Issues, ReconcileIssue{
+				Address:  address,
+				Problem:  ReconcileUnknownWallet,
+				Expected: incoming[address] - outgoing[address],
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
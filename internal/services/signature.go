@@ -0,0 +1,146 @@
+// Package services содержит бизнес-логику для работы с подписями переводов
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Определение возможных ошибок при проверке подписи перевода
+var (
+	ErrSignatureRequired   = errors.New("signature required")     // Ошибка: у кошелька отправителя зарегистрирован ключ, а подпись не передана
+	ErrInvalidSignature    = errors.New("invalid signature")      // Ошибка: подпись не соответствует ключу кошелька
+	ErrInvalidNonce        = errors.New("invalid nonce")          // Ошибка: nonce не больше nonce последнего подписанного списания
+	ErrInvalidPublicKey    = errors.New("invalid public key")     // Ошибка: ключ не является публичным ключом Ed25519 в hex
+	ErrPublicKeyAlreadySet = errors.New("public key already set") // Ошибка: у кошелька уже зарегистрирован ключ
+)
+
+// Signature содержит подпись перевода или вывода средств ключом Ed25519 кошелька отправителя
+//
+// Поля:
+//   - Nonce (uint64) — должен быть больше nonce последнего подписанного списания с кошелька
+//   - Value (string) — подпись сообщения TransferMessage или WithdrawalMessage в hex (128 символов)
+type Signature struct {
+	Nonce uint64
+	Value string
+}
+
+// TransferMessage возвращает каноническое представление перевода, которое подписывает владелец кошелька
+//
+// Формат (строки разделены "\n", сумма - в минимальных единицах валюты):
+//
+//	transfer:v2
+//	from:<адрес отправителя>
+//	to:<адрес получателя>
+//	amount:<сумма>
+//	nonce:<nonce>
+//	details:<хеш сведений о переводе, см. TransferDetails.Digest>
+func TransferMessage(from, to string, amount int64, nonce uint64, details TransferDetails) []byte {
+	return []byte(fmt.Sprintf("transfer:v2\nfrom:%s\nto:%s\namount:%d\nnonce:%d\ndetails:%s",
+		from, to, amount, nonce, details.Digest()))
+}
+
+// Digest возвращает SHA-256 (hex) канонического представления сведений о переводе
+//
+// Каждое поле записывается строкой с длиной значения в байтах, поэтому разделители внутри значений
+// не влияют на разбор; пары метаданных следуют в порядке возрастания ключей:
+//
+//	memo:<длина>:<комментарий>
+//	reference:<длина>:<внешний идентификатор>
+//	meta:<длина>:<ключ>:<длина>:<значение>
+//
+// Пустые сведения тоже хешируются, подпись без сведений подтверждает, что их нет
+func (d *TransferDetails) Digest() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "memo:%d:%s\n", len(d.Memo), d.Memo)
+	fmt.Fprintf(&b, "reference:%d:%s\n", len(d.Reference), d.Reference)
+	for _, k := range slices.Sorted(maps.Keys(d.Metadata)) {
+		v := d.Metadata[k]
+		fmt.Fprintf(&b, "meta:%d:%s:%d:%s\n", len(k), k, len(v), v)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParsePublicKey декодирует публичный ключ Ed25519 из hex
+//
+// Возвращает:
+//   - ed25519.PublicKey: ключ
+//   - error: ErrInvalidPublicKey, если строка не является ключом Ed25519 в hex
+func ParsePublicKey(value string) (ed25519.PublicKey, error) {
+	b, err := hex.DecodeString(value)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// WithdrawalMessage возвращает каноническое представление вывода средств, которое подписывает владелец кошелька
+//
+// Формат (строки разделены "\n", сумма - в минимальных единицах валюты):
+//
+//	withdrawal:v1
+//	from:<адрес кошелька>
+//	amount:<сумма>
+//	external_id:<идентификатор платежа у провайдера>
+//	nonce:<nonce>
+//
+// Отдельный префикс не позволяет выдать подпись перевода за подпись вывода и наоборот
+func WithdrawalMessage(from string, amount int64, externalID string, nonce uint64) []byte {
+	return []byte(fmt.Sprintf("withdrawal:v1\nfrom:%s\namount:%d\nexternal_id:%s\nnonce:%d", from, amount, externalID, nonce))
+}
+
+// signedDebit - подпись списания с кошелька вместе с сообщением, которое она должна подтверждать
+type signedDebit struct {
+	sig     *Signature
+	message []byte
+}
+
+// signDebit связывает подпись sig с сообщением message(sig.Nonce)
+//
+// Возвращает nil, если подпись не передана
+func signDebit(sig *Signature, message func(nonce uint64) []byte) *signedDebit {
+	if sig == nil || sig.Value == "" {
+		return nil
+	}
+	return &signedDebit{sig: sig, message: message(sig.Nonce)}
+}
+
+// verifyDebitSignature проверяет подпись списания (перевода или вывода средств) с кошелька,
+// у которого зарегистрирован ключ
+//
+// Логика работы:
+//  1. Если у кошелька нет ключа, подпись не требуется
+//  2. Проверка, что подпись передана и nonce больше nonce последнего подписанного списания
+//  3. Проверка подписи сообщения списания (TransferMessage или WithdrawalMessage) ключом кошелька
+func verifyDebitSignature(wallet *models.Wallet, signed *signedDebit) error {
+	if wallet.PublicKey == "" {
+		return nil
+	}
+	if signed == nil {
+		return ErrSignatureRequired
+	}
+	if signed.sig.Nonce <= wallet.Nonce {
+		return ErrInvalidNonce
+	}
+
+	pub, err := ParsePublicKey(wallet.PublicKey)
+	if err != nil {
+		return err
+	}
+	value, err := hex.DecodeString(signed.sig.Value)
+	if err != nil || len(value) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+	if !ed25519.Verify(pub, signed.message, value) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Package services содержит бизнес-логику для работы с транзакциями и кошельками
+package services
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer создаёт спаны для функций сервисного слоя
+var tracer = otel.Tracer("github.com/normalniydada/test_task_infotecs/internal/services")
+
+// startSpan начинает дочерний спан с указанным именем и атрибутами
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan завершает спан, отмечая его ошибкой, если она произошла
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, ErrorCode(err))
+	}
+	span.End()
+}
@@ -2,10 +2,19 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
+	"github.com/normalniydada/test_task_infotecs/internal/events"
+	"github.com/normalniydada/test_task_infotecs/internal/metrics"
 	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
 )
 
 // Определение возможных ошибок при переводе средств
@@ -15,17 +24,86 @@ var (
 	ErrNotEnoughMoney   = errors.New("not enough money")   // Ошибка: недостаточно средств на балансе отправителя
 	ErrSelfTransfer     = errors.New("self transfer")      // Ошибка: невозможно отправить средства самому себе
 	ErrInvalidAmount    = errors.New("invalid amount")     // Ошибка: сумма перевода должна быть больше 0
+	ErrSystemWallet     = errors.New("system wallet")      // Ошибка: системный кошелёк не участвует в переводах клиентов
+	ErrMemoTooLong      = errors.New("memo too long")      // Ошибка: комментарий длиннее maxMemo символов
+	ErrInvalidReference = errors.New("invalid reference")  // Ошибка: внешний идентификатор длиннее maxReference
+	ErrInvalidMetadata  = errors.New("invalid metadata")   // Ошибка: метаданные превышают ограничения maxMetadata*
+	ErrReferenceExists  = errors.New("reference exists")   // Ошибка: внешний идентификатор уже использован кошельком отправителя
+
+	ErrTransactionNotFound = errors.New("transaction not found") // Ошибка: транзакция не найдена
+	ErrInvalidStatusFilter = errors.New("invalid status filter") // Ошибка: неизвестное состояние в фильтре истории
+)
+
+// StatusFilterAll - значение фильтра истории по состоянию, при котором возвращаются все транзакции,
+// включая отклонённые попытки
+const StatusFilterAll = "all"
+
+// historyStatuses - состояния, по которым можно отфильтровать историю транзакций
+var historyStatuses = []string{
+	models.TransactionPending,
+	models.TransactionCompleted,
+	models.TransactionFailed,
+	models.TransactionReversed,
+}
+
+// Ограничения сведений отправителя о переводе
+const (
+	maxMemo          = 500 // Максимальная длина комментария в символах
+	maxReference     = 128 // Максимальная длина внешнего идентификатора в байтах
+	maxMetadataKeys  = 20  // Максимальное число ключей метаданных
+	maxMetadataKey   = 40  // Максимальная длина ключа метаданных в байтах
+	maxMetadataValue = 500 // Максимальная длина значения метаданных в байтах
 )
 
+// TransferDetails - необязательные сведения отправителя о переводе, сохраняемые вместе с транзакцией
+//
+// Поля:
+//   - Memo (string) — произвольный комментарий, не длиннее maxMemo символов
+//   - Reference (string) — внешний идентификатор перевода; уникален для кошелька отправителя среди
+//     неотклонённых транзакций, по нему выполняется поиск (см. GetLastNTransactions)
+//   - Metadata (map[string]string) — не более maxMetadataKeys пар; ключи непустые, не длиннее maxMetadataKey,
+//     значения не длиннее maxMetadataValue
+type TransferDetails struct {
+	Memo      string
+	Reference string
+	Metadata  map[string]string
+}
+
+// validate проверяет сведения о переводе
+func (d *TransferDetails) validate() error {
+	if utf8.RuneCountInString(d.Memo) > maxMemo {
+		return ErrMemoTooLong
+	}
+	if len(d.Reference) > maxReference {
+		return ErrInvalidReference
+	}
+	if len(d.Metadata) > maxMetadataKeys {
+		return ErrInvalidMetadata
+	}
+	for k, v := range d.Metadata {
+		if k == "" || len(k) > maxMetadataKey || len(v) > maxMetadataValue {
+			return ErrInvalidMetadata
+		}
+	}
+	return nil
+}
+
 // TransferMoney выполняет перевод средств между двумя кошельками с учётом конкурентного доступа.
 //
 // Функция использует GORM-транзакцию и блокировку `FOR UPDATE` для предотвращения race condition.
 //
 // Параметры:
+//   - ctx (context.Context): контекст запроса, при отмене которого выполнение SQL-запросов прерывается.
 //   - db (*gorm.DB): подключение к базе данных.
 //   - from (string): адрес кошелька отправителя.
 //   - to (string): адрес кошелька получателя.
 //   - amount (int64): сумма перевода в минимальных единицах валюты (например, копейки).
+//   - details (TransferDetails): комментарий, внешний идентификатор и метаданные отправителя; могут быть пустыми.
+//   - sig (*Signature): подпись перевода; обязательна, если у кошелька отправителя зарегистрирован ключ Ed25519.
+//
+// Возвращает:
+//   - *models.Transaction: созданная запись транзакции.
+//   - error: ошибку перевода или nil.
 //
 // Возможные ошибки:
 //   - ErrInvalidAmount: если сумма перевода <= 0.
@@ -33,93 +111,426 @@ var (
 //   - ErrSenderNotFound: если кошелек отправителя не найден в базе данных.
 //   - ErrReceiverNotFound: если кошелек получателя не найден в базе данных.
 //   - ErrNotEnoughMoney: если у отправителя недостаточно средств.
+//   - ErrWalletFrozen: если кошелек отправителя или получателя заморожен.
+//   - ErrSystemWallet: если отправитель или получатель - системный кошелёк.
+//   - ErrSignatureRequired, ErrInvalidNonce, ErrInvalidSignature: если подпись перевода отсутствует или некорректна.
+//   - ErrMemoTooLong, ErrInvalidReference, ErrInvalidMetadata: если сведения о переводе превышают ограничения.
+//   - ErrReferenceExists: если кошелёк отправителя уже использовал внешний идентификатор.
+//
+// Отклонённая попытка сохраняется транзакцией в состоянии TransactionFailed с кодом ошибки, кроме отказов
+// проверки входных данных и несуществующих кошельков (см. recordFailedTransfer).
 //
 // Логика работы:
-//  1. Проверка, что сумма > 0 и кошельки отправителя и получателя разные
+//  1. Проверка сведений о переводе, того, что сумма > 0 и кошельки отправителя и получателя разные
 //  2. Использование `db.Transaction()`, чтобы выполнить перевод атомарно
-//  3. Блокирование записи `FOR UPDATE`, чтобы избежать состояния гонки
-//  4. Проверка наличия средств у отправителя перед уменьшением баланса
-//  5. Обновление балансов отправителя и получателя
-//  6. Создание записи транзакции в базе данных
-//  7. В случае ошибки откат изменений
-func TransferMoney(db *gorm.DB, from string, to string, amount int64) error {
+//  3. Блокирование записи `FOR UPDATE`, чтобы избежать состояния гонки (в отдельном спане `lock_wallets`)
+//  4. Проверка, что ни один из кошельков не системный и не заморожен, и проверка подписи и nonce, если у отправителя зарегистрирован ключ,
+//     и уникальности внешнего идентификатора
+//  5. Проверка наличия средств у отправителя перед уменьшением баланса
+//  6. Обновление балансов отправителя и получателя (и nonce отправителя для подписанных переводов)
+//  7. Создание записи транзакции, событий вебхуков (transactional outbox) и записи журнала аудита в базе данных
+//  8. В случае ошибки откат изменений
+//  9. После фиксации публикация транзакции в шину событий для потоковых API
+//
+// Шаги 2-9 выполняет postTransaction, общий для переводов, ручных корректировок, пополнений и выводов.
+// Результат каждой попытки и время ожидания блокировок учитываются в метриках и спанах OpenTelemetry
+func TransferMoney(ctx context.Context, db *gorm.DB, from string, to string, amount int64, details TransferDetails, sig *Signature) (_ *models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "services.TransferMoney",
+		attribute.String("wallet.from", from),
+		attribute.String("wallet.to", to),
+		attribute.Int64("transfer.amount", amount),
+	)
+	transaction := models.Transaction{
+		From:   from,
+		To:     to,
+		Amount: amount,
+		Type:   models.TransactionTransfer,
+	}
+	defer func() {
+		if err != nil {
+			if recErr := recordFailedTransfer(ctx, db, transaction, err); recErr != nil {
+				span.RecordError(recErr)
+			}
+		}
+		metrics.ObserveTransfer(transferOutcome(err), amount)
+		endSpan(span, err)
+	}()
+
+	if err = details.validate(); err != nil {
+		return nil, err
+	}
+	transaction.Memo, transaction.Reference, transaction.Metadata = details.Memo, details.Reference, details.Metadata
+
 	if amount <= 0 {
-		return ErrInvalidAmount
+		return nil, ErrInvalidAmount
 	}
 
 	if from == to {
-		return ErrSelfTransfer
+		return nil, ErrSelfTransfer
 	}
 
-	return db.Transaction(func(tx *gorm.DB) error {
-		var fromWallet, toWallet models.Wallet
+	signed := signDebit(sig, func(nonce uint64) []byte { return TransferMessage(from, to, amount, nonce, details) })
+	return postTransaction(ctx, db, transaction, signed)
+}
+
+// unrecordedFailures - коды отказов, при которых попытка перевода не сохраняется: ошибки проверки
+// входных данных (в том числе несуществующие кошельки) не относятся к состоянию кошелька и
+// иначе позволяли бы без ограничений наполнять журнал операций и аудита, а сбои БД, отмена
+// запроса и таймаут возникают до того, как попытка дошла до проверок
+var unrecordedFailures = []string{
+	"invalid_amount", "self_transfer", "sender_not_found", "receiver_not_found",
+	"memo_too_long", "invalid_reference", "invalid_metadata",
+	"internal", "canceled", "timeout",
+}
+
+// recordFailedTransfer сохраняет отклонённую попытку перевода в состоянии TransactionFailed с кодом ошибки cause
+// и записью журнала аудита, чтобы поддержка видела причину отказа. Балансы не изменяются.
+// Отказы с кодами из unrecordedFailures не сохраняются
+func recordFailedTransfer(ctx context.Context, db *gorm.DB, transaction models.Transaction, cause error) error {
+	code := ErrorCode(cause)
+	if slices.Contains(unrecordedFailures, code) {
+		return nil
+	}
 
-		// Блокирование кошелька отправителя
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("address = ?", from).
-			First(&fromWallet).
-			Error; err != nil {
-			return ErrSenderNotFound
+	transaction.Status, transaction.ErrorCode = models.TransactionFailed, code
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
 		}
+		return audit.Record(tx, audit.ActionTransferFail, audit.EntityTransaction, strconv.FormatUint(uint64(transaction.ID), 10),
+			nil, map[string]any{
+				"from":       transaction.From,
+				"to":         transaction.To,
+				"amount":     transaction.Amount,
+				"error_code": transaction.ErrorCode,
+			})
+	})
+}
+
+// postTransaction проводит транзакцию между двумя кошельками в отдельной транзакции базы данных
+// (см. applyTransaction) и после фиксации публикует её в шину событий для потоковых API
+func postTransaction(ctx context.Context, db *gorm.DB, transaction models.Transaction, signed *signedDebit) (*models.Transaction, error) {
+	var event events.Transfer
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		event, err = applyTransaction(tx, transaction, signed)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Уведомление потоковых подписчиков только после фиксации транзакции
+	events.Publish(event)
+	return &event.Transaction, nil
+}
+
+// applyTransaction проводит транзакцию между двумя кошельками внутри транзакции базы данных tx. Это единый путь
+// записи в журнал операций для переводов, ручных корректировок, пополнений и выводов средств: блокировки, проверки,
+// изменение балансов, outbox вебхуков и журнал аудита выполняются одинаково
+//
+// Параметры:
+//   - tx (*gorm.DB): транзакция базы данных
+//   - transaction (models.Transaction): From, To, Amount, Type, для корректировок ReasonCode и Note; Status
+//     по умолчанию TransactionCompleted
+//   - signed (*signedDebit): подпись списания (см. signDebit); проверяется только для переводов и выводов средств
+//
+// Отличия по типу транзакции:
+//   - TransactionTransfer: системные кошельки запрещены (ErrSystemWallet), проверяются подпись, nonce и
+//     уникальность внешнего идентификатора (ErrReferenceExists)
+//   - TransactionWithdrawal: проверяются подпись и nonce, как у переводов
+//   - остальные типы: ровно один из кошельков должен быть системным (ErrSystemWallet)
+//   - TransactionRefund: возврат средств проводится и на замороженный кошелёк
+//
+// Баланс системного кошелька может стать отрицательным, баланс остальных - нет (ErrNotEnoughMoney).
+// Проверки выполняются до изменения данных, поэтому после ошибки проверки tx можно продолжать использовать.
+// Возвращает событие для events.Publish, которое вызывающий публикует после фиксации tx
+func applyTransaction(tx *gorm.DB, transaction models.Transaction, signed *signedDebit) (events.Transfer, error) {
+	from, to, amount := transaction.From, transaction.To, transaction.Amount
+	transfer := transaction.Type == models.TransactionTransfer
+	// Списания по инициативе владельца кошелька подтверждаются его подписью, если у кошелька есть ключ
+	signable := transfer || transaction.Type == models.TransactionWithdrawal
+
+	var fromWallet, toWallet models.Wallet
+	lockStart := time.Now()
+	lockCtx, lockSpan := startSpan(tx.Statement.Context, "services.applyTransaction.lock_wallets")
 
-		// Блокирование кошелька получателя
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("address = ?", to).
-			First(&toWallet).
-			Error; err != nil {
-			return ErrReceiverNotFound
+	// Блокирование кошелька отправителя
+	if err := tx.WithContext(lockCtx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("address = ?", from).
+		First(&fromWallet).
+		Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = ErrSenderNotFound
 		}
+		endSpan(lockSpan, err)
+		return events.Transfer{}, err
+	}
 
-		// Проверка баланса отправителя перед списанием
-		if fromWallet.Balance < amount {
-			return ErrNotEnoughMoney
+	// Блокирование кошелька получателя
+	if err := tx.WithContext(lockCtx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("address = ?", to).
+		First(&toWallet).
+		Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = ErrReceiverNotFound
 		}
+		endSpan(lockSpan, err)
+		return events.Transfer{}, err
+	}
+	metrics.ObserveLockWait(time.Since(lockStart))
+	endSpan(lockSpan, nil)
 
-		// Списание средств с кошелька отправителя
-		if err := tx.Model(&fromWallet).
-			Update("balance", gorm.Expr("balance - ?", amount)).
-			Error; err != nil {
-			return err
+	// Клиентские переводы не затрагивают системные кошельки, остальные операции проходят только через них
+	if (transfer && (fromWallet.System || toWallet.System)) || (!transfer && fromWallet.System == toWallet.System) {
+		return events.Transfer{}, ErrSystemWallet
+	}
+
+	// Переводы с замороженных и на замороженные кошельки запрещены
+	if transaction.Type != models.TransactionRefund && (fromWallet.Frozen || toWallet.Frozen) {
+		return events.Transfer{}, ErrWalletFrozen
+	}
+
+	// Проверка подписи перевода или вывода средств
+	if signable {
+		if err := verifyDebitSignature(&fromWallet, signed); err != nil {
+			return events.Transfer{}, err
 		}
+	}
 
-		// Начисление средств получателю
-		if err := tx.Model(&toWallet).
-			Update("balance", gorm.Expr("balance + ?", amount)).
-			Error; err != nil {
-			return err
+	// Проверка уникальности внешнего идентификатора; блокировка кошелька отправителя исключает гонку
+	// между переводами с одним идентификатором, уникальный индекс защищает от прочих записей
+	if transaction.Reference != "" {
+		var used int64
+		if err := tx.Model(&models.Transaction{}).
+			Where(`"from" = ? AND reference = ? AND status <> ?`, from, transaction.Reference, models.TransactionFailed).
+			Count(&used).Error; err != nil {
+			return events.Transfer{}, err
+		}
+		if used > 0 {
+			return events.Transfer{}, ErrReferenceExists
 		}
+	}
+
+	// Проверка баланса отправителя перед списанием
+	if !fromWallet.System && fromWallet.Balance < amount {
+		return events.Transfer{}, ErrNotEnoughMoney
+	}
 
-		// Создание записи транзакции
-		transaction := models.Transaction{
-			From:   from,
-			To:     to,
-			Amount: amount,
+	// Списание средств с кошелька отправителя
+	debit := map[string]any{"balance": gorm.Expr("balance - ?", amount)}
+	if signable && fromWallet.PublicKey != "" {
+		debit["nonce"] = signed.sig.Nonce
+	}
+	if err := tx.Model(&fromWallet).
+		Updates(debit).
+		Error; err != nil {
+		return events.Transfer{}, err
+	}
+
+	// Начисление средств получателю
+	if err := tx.Model(&toWallet).
+		Update("balance", gorm.Expr("balance + ?", amount)).
+		Error; err != nil {
+		return events.Transfer{}, err
+	}
+
+	// Создание записи транзакции
+	if transaction.Status == "" {
+		transaction.Status = models.TransactionCompleted
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return events.Transfer{}, err
+	}
+	// Постановка событий вебхуков в outbox в той же транзакции
+	if err := enqueueTransferWebhooks(tx, &transaction); err != nil {
+		return events.Transfer{}, err
+	}
+
+	event := events.Transfer{
+		Transaction: transaction,
+		FromBalance: fromWallet.Balance - amount,
+		ToBalance:   toWallet.Balance + amount,
+	}
+
+	// Запись в журнал аудита
+	action := audit.ActionTransferCreate
+	after := map[string]any{
+		"from":         from,
+		"to":           to,
+		"amount":       amount,
+		"from_balance": event.FromBalance,
+		"to_balance":   event.ToBalance,
+	}
+	switch transaction.Type {
+	case models.TransactionTransfer:
+		if transaction.Memo != "" {
+			after["memo"] = transaction.Memo
 		}
+		if transaction.Reference != "" {
+			after["reference"] = transaction.Reference
+		}
+		if len(transaction.Metadata) > 0 {
+			after["metadata"] = transaction.Metadata
+		}
+	case models.TransactionAdjustment:
+		action = audit.ActionAdjustmentCreate
+		after["reason_code"] = transaction.ReasonCode
+		after["note"] = transaction.Note
+	default:
+		action = audit.ActionPaymentTransfer
+		after["type"] = transaction.Type
+	}
+	err := audit.Record(tx, action, audit.EntityTransaction, strconv.FormatUint(uint64(transaction.ID), 10),
+		map[string]any{
+			"from_balance": fromWallet.Balance,
+			"to_balance":   toWallet.Balance,
+		},
+		after,
+	)
+	return event, err
+}
 
-		return tx.Create(&transaction).Error
-	})
+// transferOutcome возвращает метку результата перевода для метрик
+func transferOutcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	return ErrorCode(err)
 }
 
 // GetLastNTransactions получает последние N транзакций из базы данных
 //
 // Параметры:
+//   - ctx (context.Context): контекст запроса
 //   - db (*gorm.DB): подключение к базе данных
 //   - count (int): количество транзакций, которые необходимо вернуть
+//   - wallets ([]string): если не nil, возвращаются только транзакции, где отправитель или получатель входит в список
+//   - reference (string): если не пусто, возвращаются только транзакции с этим внешним идентификатором
+//   - status (string): состояние транзакций; пусто - все, кроме отклонённых попыток, StatusFilterAll - все
 //
 // Возвращает:
 //   - []models.Transaction: массив последних N транзакций, отсортированных по убыванию времени создания
-//   - error: ошибку при выполнении запроса или nil, если всё прошло успешно
+//   - error: ErrInvalidStatusFilter, ошибку при выполнении запроса или nil, если всё прошло успешно
 //
 // Логика работы:
-//  1. Выполннение SQL-запроса с сортировкой `ORDER BY created_at DESC`
+//  1. Выполннение SQL-запроса с сортировкой `ORDER BY created_at DESC` (с фильтрами по кошелькам, внешнему
+//     идентификатору и состоянию; без явного состояния отклонённые попытки `failed` исключаются)
 //  2. Ограничение количества результатов `LIMIT count`
 //  3. Заполнение слайса `transactions` полученными данными
 //  4. Возвращение полученных транзакций или ошибки при запросе
-func GetLastNTransactions(db *gorm.DB, count int) ([]models.Transaction, error) {
+func GetLastNTransactions(ctx context.Context, db *gorm.DB, count int, wallets []string, reference string, status string) (_ []models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "services.GetLastNTransactions", attribute.Int("count", count))
+	defer func() { endSpan(span, err) }()
+
+	query := db.WithContext(ctx)
+	switch {
+	case status == "":
+		query = query.Where("status <> ?", models.TransactionFailed)
+	case status == StatusFilterAll:
+	case slices.Contains(historyStatuses, status):
+		query = query.Where("status = ?", status)
+	default:
+		return nil, ErrInvalidStatusFilter
+	}
+	if wallets != nil {
+		query = query.Where(`("from" IN ? OR "to" IN ?)`, wallets, wallets)
+	}
+	if reference != "" {
+		query = query.Where("reference = ?", reference)
+	}
+
 	var transactions []models.Transaction
-	err := db.Order("created_at desc").Limit(count).Find(&transactions).Error
+	err = query.Order("created_at desc").Limit(count).Find(&transactions).Error
 	if err != nil {
 		return nil, err
 	}
 	return transactions, nil
 }
+
+// GetTransactionsAfter получает проведённые транзакции с ID больше указанного в порядке возрастания ID.
+// Используется для догоняющей выдачи пропущенных событий при переподключении к потоку транзакций;
+// отклонённые попытки в поток не публикуются и здесь не возвращаются
+//
+// Параметры:
+//   - ctx (context.Context): контекст запроса
+//   - db (*gorm.DB): подключение к базе данных
+//   - afterID (uint): ID последней полученной клиентом транзакции
+//   - wallets ([]string): если не nil, возвращаются только транзакции, где отправитель или получатель входит в список
+//   - limit (int): максимальное количество транзакций
+//
+// Возвращает:
+//   - []models.Transaction: транзакции, отсортированные по возрастанию ID
+//   - error: ошибку при выполнении запроса
+func GetTransactionsAfter(ctx context.Context, db *gorm.DB, afterID uint, wallets []string, limit int) (_ []models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "services.GetTransactionsAfter", attribute.Int64("after_id", int64(afterID)))
+	defer func() { endSpan(span, err) }()
+
+	query := db.WithContext(ctx).Where("id > ? AND status <> ?", afterID, models.TransactionFailed)
+	if wallets != nil {
+		query = query.Where(`("from" IN ? OR "to" IN ?)`, wallets, wallets)
+	}
+
+	var transactions []models.Transaction
+	if err = query.Order("id asc").Limit(limit).Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetTransaction получает транзакцию по идентификатору
+//
+// Возвращает:
+//   - *models.Transaction: найденная транзакция
+//   - error: ErrTransactionNotFound, если транзакция не найдена; другую ошибку при сбое БД
+func GetTransaction(ctx context.Context, db *gorm.DB, id uint) (_ *models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "services.GetTransaction", attribute.Int64("transaction.id", int64(id)))
+	defer func() { endSpan(span, err) }()
+
+	var transaction models.Transaction
+	if err = db.WithContext(ctx).First(&transaction, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// EachTransaction передаёт транзакции за период в fn пачками по возрастанию ID
+//
+// Используется для выгрузки истории без загрузки всех транзакций в память.
+//
+// Параметры:
+//   - since, until (time.Time): границы периода по времени создания (until не включается); нулевые - без ограничения
+//   - batchSize (int): размер пачки
+//   - fn: обработчик пачки; ошибка обработчика прерывает выгрузку и возвращается
+func EachTransaction(ctx context.Context, db *gorm.DB, since, until time.Time, batchSize int, fn func([]models.Transaction) error) (err error) {
+	ctx, span := startSpan(ctx, "services.EachTransaction")
+	defer func() { endSpan(span, err) }()
+
+	query := db.WithContext(ctx)
+	if !since.IsZero() {
+		query = query.Where("created_at >= ?", since)
+	}
+	if !until.IsZero() {
+		query = query.Where("created_at < ?", until)
+	}
+	// Новая сессия, чтобы условия каждой пачки не накапливались в общем запросе
+	query = query.Session(&gorm.Session{})
+
+	var lastID uint
+	for {
+		var batch []models.Transaction
+		if err = query.Where("id > ?", lastID).Order("id asc").Limit(batchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err = fn(batch); err != nil {
+			return err
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
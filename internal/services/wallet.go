@@ -2,16 +2,36 @@
 package services
 
 import (
+	"context"
+	"encoding/hex"
 	"errors"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
 	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"slices"
+	"unicode/utf8"
 )
 
-var ErrWalletNotFound = errors.New("wallet not found") // Ошибка: кошелек с указанным адресом не найден
+// Определение возможных ошибок при работе с кошельками
+var (
+	ErrWalletNotFound = errors.New("wallet not found")      // Ошибка: кошелек с указанным адресом не найден
+	ErrWalletExists   = errors.New("wallet already exists") // Ошибка: кошелек с таким адресом уже существует
+	ErrWalletFrozen   = errors.New("wallet frozen")         // Ошибка: кошелек заморожен, переводы запрещены
+
+	ErrReasonRequired    = errors.New("adjustment reason required")     // Ошибка: не указаны код причины или комментарий корректировки
+	ErrInvalidReasonCode = errors.New("invalid adjustment reason code") // Ошибка: код причины корректировки не из models.AdjustmentReasonCodes
+	ErrNoteTooLong       = errors.New("adjustment note too long")       // Ошибка: комментарий корректировки длиннее maxAdjustmentNote
+)
+
+// maxAdjustmentNote - максимальная длина комментария ручной корректировки в символах
+const maxAdjustmentNote = 500
 
 // GetWalletBalance получает баланс кошелька по его адресу
 //
 // Параметры:
+//   - ctx (context.Context): контекст запроса
 //   - db (*gorm.DB): подключение к базе данных
 //   - address (string): адрес кошелька, баланс которого нужно получить
 //
@@ -24,9 +44,12 @@ var ErrWalletNotFound = errors.New("wallet not found") // Ошибка: коше
 //  2. Если кошелек найден, возвращается его баланс
 //  3. Если кошелек отсутствует, возвращается ErrWalletNotFound
 //  4. Возврат ошибки, в случае возникновения ее в базе данных
-func GetWalletBalance(db *gorm.DB, address string) (int64, error) {
+func GetWalletBalance(ctx context.Context, db *gorm.DB, address string) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "services.GetWalletBalance", attribute.String("wallet.address", address))
+	defer func() { endSpan(span, err) }()
+
 	var wallet models.Wallet
-	if err := db.Where("address = ?", address).First(&wallet).Error; err != nil {
+	if err = db.WithContext(ctx).Where("address = ?", address).First(&wallet).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return 0, ErrWalletNotFound
 		}
@@ -34,3 +57,265 @@ func GetWalletBalance(db *gorm.DB, address string) (int64, error) {
 	}
 	return wallet.Balance, nil
 }
+
+// GetWallet получает кошелёк по его адресу
+//
+// Возвращает:
+//   - *models.Wallet: кошелёк, включая публичный ключ и nonce последнего подписанного перевода
+//   - error: ErrWalletNotFound, если кошелек не найден; другую ошибку, если произошел сбой в БД
+func GetWallet(ctx context.Context, db *gorm.DB, address string) (_ *models.Wallet, err error) {
+	ctx, span := startSpan(ctx, "services.GetWallet", attribute.String("wallet.address", address))
+	defer func() { endSpan(span, err) }()
+
+	var wallet models.Wallet
+	if err = db.WithContext(ctx).Where("address = ?", address).First(&wallet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// ListWallets возвращает все кошельки в порядке адресов
+func ListWallets(ctx context.Context, db *gorm.DB) (_ []models.Wallet, err error) {
+	ctx, span := startSpan(ctx, "services.ListWallets")
+	defer func() { endSpan(span, err) }()
+
+	var wallets []models.Wallet
+	if err = db.WithContext(ctx).Order("address").Find(&wallets).Error; err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// CreateWallet создаёт кошелёк с нулевым балансом
+//
+// Параметры:
+//   - ctx (context.Context): контекст запроса
+//   - db (*gorm.DB): подключение к базе данных
+//   - publicKey (string): публичный ключ Ed25519 в hex; если задан, адрес кошелька выводится из ключа,
+//     иначе генерируется случайный адрес
+//
+// Возвращает:
+//   - *models.Wallet: созданный кошелёк
+//   - error: ErrInvalidPublicKey, если ключ некорректен; ErrWalletExists, если кошелёк для ключа уже создан
+func CreateWallet(ctx context.Context, db *gorm.DB, publicKey string) (_ *models.Wallet, err error) {
+	ctx, span := startSpan(ctx, "services.CreateWallet")
+	defer func() { endSpan(span, err) }()
+
+	var wallet models.Wallet
+	if publicKey == "" {
+		wallet.CreateWalletAddress()
+	} else {
+		pub, err := ParsePublicKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		wallet.CreateWalletAddressFromKey(pub)
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&wallet)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWalletExists
+		}
+		return audit.Record(tx, audit.ActionWalletCreate, audit.EntityWallet, wallet.Address, nil, WalletState(&wallet))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// RegisterPublicKey регистрирует публичный ключ Ed25519 для существующего кошелька
+//
+// После регистрации все переводы с кошелька должны быть подписаны этим ключом.
+// Заменить зарегистрированный ключ нельзя.
+//
+// Возвращает:
+//   - *models.Wallet: кошелёк с зарегистрированным ключом
+//   - error: ErrInvalidPublicKey, ErrWalletNotFound или ErrPublicKeyAlreadySet
+func RegisterPublicKey(ctx context.Context, db *gorm.DB, address string, publicKey string) (_ *models.Wallet, err error) {
+	ctx, span := startSpan(ctx, "services.RegisterPublicKey", attribute.String("wallet.address", address))
+	defer func() { endSpan(span, err) }()
+
+	pub, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	publicKey = hex.EncodeToString(pub)
+
+	var wallet models.Wallet
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("address = ?", address).
+			First(&wallet).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+		if wallet.PublicKey != "" {
+			return ErrPublicKeyAlreadySet
+		}
+
+		if err := tx.Model(&wallet).Update("public_key", publicKey).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActionWalletKeySet, audit.EntityWallet, address,
+			map[string]any{"public_key": nil},
+			map[string]any{"public_key": publicKey},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// SetWalletFrozen замораживает кошелёк или снимает заморозку
+//
+// Пока кошелёк заморожен, TransferMoney отклоняет переводы с него и на него с ошибкой ErrWalletFrozen.
+// Повторная заморозка уже замороженного кошелька не изменяет его и не пишется в журнал аудита.
+//
+// Параметры:
+//   - ctx (context.Context): контекст запроса
+//   - db (*gorm.DB): подключение к базе данных
+//   - address (string): адрес кошелька
+//   - frozen (bool): true - заморозить, false - снять заморозку
+//
+// Возвращает:
+//   - *models.Wallet: кошелёк после изменения
+//   - error: ErrWalletNotFound, если кошелёк не найден; другую ошибку при сбое БД
+func SetWalletFrozen(ctx context.Context, db *gorm.DB, address string, frozen bool) (_ *models.Wallet, err error) {
+	ctx, span := startSpan(ctx, "services.SetWalletFrozen",
+		attribute.String("wallet.address", address),
+		attribute.Bool("wallet.frozen", frozen),
+	)
+	defer func() { endSpan(span, err) }()
+
+	var wallet models.Wallet
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("address = ?", address).
+			First(&wallet).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+		if wallet.Frozen == frozen {
+			return nil
+		}
+
+		if err := tx.Model(&wallet).Update("frozen", frozen).Error; err != nil {
+			return err
+		}
+		action := audit.ActionWalletFreeze
+		if !frozen {
+			action = audit.ActionWalletUnfreeze
+		}
+		return audit.Record(tx, action, audit.EntityWallet, address,
+			map[string]any{"frozen": !frozen},
+			map[string]any{"frozen": frozen},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// AdjustBalance выполняет ручную корректировку баланса кошелька
+//
+// Корректировка проводится как транзакция типа models.TransactionAdjustment между кошельком и системным
+// кошельком капитала (models.EquityWalletAddress) тем же путём, что и перевод (см. postTransaction):
+// начисление - с кошелька капитала на кошелёк, списание - с кошелька на кошелёк капитала. Поэтому корректировка
+// попадает в историю транзакций кошелька, вебхуки, потоковые API и сверку балансов.
+//
+// Параметры:
+//   - ctx (context.Context): контекст выполнения; субъект аудита берётся из audit.Meta
+//   - db (*gorm.DB): подключение к базе данных
+//   - address (string): адрес кошелька
+//   - delta (int64): изменение баланса в копейках (положительное - начисление, отрицательное - списание)
+//   - reasonCode (string): код причины из models.AdjustmentReasonCodes
+//   - note (string): комментарий администратора, обязателен
+//
+// Возвращает:
+//   - *models.Transaction: транзакция корректировки
+//   - error: ErrInvalidAmount, ErrReasonRequired, ErrInvalidReasonCode, ErrNoteTooLong, ErrWalletNotFound,
+//     ErrSystemWallet, ErrWalletFrozen, ErrNotEnoughMoney или ошибку БД
+//
+// Кошелёк капитала создаётся с нулевым балансом при первой корректировке
+func AdjustBalance(ctx context.Context, db *gorm.DB, address string, delta int64, reasonCode, note string) (_ *models.Transaction, err error) {
+	ctx, span := startSpan(ctx, "services.AdjustBalance",
+		attribute.String("wallet.address", address),
+		attribute.Int64("adjustment.amount", delta),
+		attribute.String("adjustment.reason_code", reasonCode),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if delta == 0 {
+		return nil, ErrInvalidAmount
+	}
+	if reasonCode == "" || note == "" {
+		return nil, ErrReasonRequired
+	}
+	if !slices.Contains(models.AdjustmentReasonCodes, reasonCode) {
+		return nil, ErrInvalidReasonCode
+	}
+	if utf8.RuneCountInString(note) > maxAdjustmentNote {
+		return nil, ErrNoteTooLong
+	}
+	if address == models.EquityWalletAddress {
+		return nil, ErrSystemWallet
+	}
+
+	if err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return ensureSystemWallet(tx, models.EquityWalletAddress)
+	}); err != nil {
+		return nil, err
+	}
+
+	transaction := models.Transaction{
+		From:       models.EquityWalletAddress,
+		To:         address,
+		Amount:     delta,
+		Type:       models.TransactionAdjustment,
+		ReasonCode: reasonCode,
+		Note:       note,
+	}
+	if delta < 0 {
+		transaction.From, transaction.To, transaction.Amount = address, models.EquityWalletAddress, -delta
+	}
+
+	result, err := postTransaction(ctx, db, transaction, nil)
+	if errors.Is(err, ErrSenderNotFound) || errors.Is(err, ErrReceiverNotFound) {
+		return nil, ErrWalletNotFound
+	}
+	return result, err
+}
+
+// ensureSystemWallet создаёт системный кошелёк с нулевым балансом и записью аудита wallet.create, если его ещё нет
+func ensureSystemWallet(tx *gorm.DB, address string) error {
+	wallet := models.Wallet{Address: address, System: true}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&wallet)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return audit.Record(tx, audit.ActionWalletCreate, audit.EntityWallet, address, nil, WalletState(&wallet))
+}
+
+// WalletState возвращает состояние кошелька для журнала аудита
+func WalletState(wallet *models.Wallet) map[string]any {
+	return map[string]any{
+		"address":    wallet.Address,
+		"balance":    wallet.Balance,
+		"public_key": wallet.PublicKey,
+		"frozen":     wallet.Frozen,
+	}
+}
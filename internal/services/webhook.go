@@ -0,0 +1,314 @@
+// Package services содержит бизнес-логику для работы с подписками на вебхуки
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"github.com/normalniydada/test_task_infotecs/internal/webhooks"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Определение возможных ошибок при работе с вебхуками
+var (
+	ErrWebhookNotFound      = errors.New("webhook not found")       // Ошибка: подписка не найдена или удалена
+	ErrWebhookEventNotFound = errors.New("webhook event not found") // Ошибка: событие вебхука не найдено
+	ErrInvalidWebhookURL    = errors.New("invalid webhook url")     // Ошибка: адрес получателя не является http(s) URL или указывает во внутреннюю сеть
+	ErrInvalidEventType     = errors.New("invalid event type")      // Ошибка: неизвестный тип события
+)
+
+// webhookEventTypes - типы событий, на которые можно подписаться
+var webhookEventTypes = []string{models.WebhookEventTransferIncoming, models.WebhookEventTransferOutgoing}
+
+// CreateWebhook создаёт подписку на события кошелька
+//
+// Параметры:
+//   - ctx (context.Context): контекст запроса
+//   - db (*gorm.DB): подключение к базе данных
+//   - wallet (string): адрес кошелька
+//   - rawURL (string): адрес получателя (http или https)
+//   - eventTypes ([]string): типы событий; пусто - все типы
+//   - secret (string): секрет подписи; пусто - генерируется случайный
+//
+// Возвращает:
+//   - *models.WebhookSubscription: созданная подписка вместе с секретом
+//   - error: ErrInvalidWebhookURL, ErrInvalidEventType, ErrWalletNotFound или ошибку БД
+func CreateWebhook(ctx context.Context, db *gorm.DB, wallet string, rawURL string, eventTypes []string, secret string) (_ *models.WebhookSubscription, err error) {
+	ctx, span := startSpan(ctx, "services.CreateWebhook", attribute.String("wallet.address", wallet))
+	defer func() { endSpan(span, err) }()
+
+	if err = validateWebhookURL(ctx, rawURL); err != nil {
+		return nil, err
+	}
+	if len(eventTypes) == 0 {
+		eventTypes = webhookEventTypes
+	}
+	for _, t := range eventTypes {
+		if !slices.Contains(webhookEventTypes, t) {
+			return nil, ErrInvalidEventType
+		}
+	}
+	if secret == "" {
+		secret = generateWebhookSecret()
+	}
+
+	sub := models.WebhookSubscription{
+		WalletAddress: wallet,
+		URL:           rawURL,
+		EventTypes:    uniqueStrings(eventTypes),
+		Secret:        secret,
+	}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkWalletsExist(tx, []string{wallet}); err != nil {
+			return err
+		}
+		if err := tx.Create(&sub).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActionWebhookCreate, audit.EntityWebhook, webhookID(sub.ID), nil, webhookState(&sub))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ListWebhooks возвращает действующие подписки
+//
+// Параметры:
+//   - wallets ([]string): если не nil, возвращаются только подписки этих кошельков
+func ListWebhooks(ctx context.Context, db *gorm.DB, wallets []string) (_ []models.WebhookSubscription, err error) {
+	ctx, span := startSpan(ctx, "services.ListWebhooks")
+	defer func() { endSpan(span, err) }()
+
+	query := db.WithContext(ctx).Where("deleted_at IS NULL")
+	if wallets != nil {
+		query = query.Where("wallet_address IN ?", wallets)
+	}
+
+	var subs []models.WebhookSubscription
+	if err = query.Order("id").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// GetWebhook возвращает действующую подписку
+//
+// Возвращает:
+//   - error: ErrWebhookNotFound, если подписка не найдена или удалена
+func GetWebhook(ctx context.Context, db *gorm.DB, id uint) (_ *models.WebhookSubscription, err error) {
+	ctx, span := startSpan(ctx, "services.GetWebhook", attribute.Int("webhook.id", int(id)))
+	defer func() { endSpan(span, err) }()
+
+	var sub models.WebhookSubscription
+	if err = db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// DeleteWebhook удаляет подписку; недоставленные события подписки помечаются как failed
+//
+// Возвращает:
+//   - error: ErrWebhookNotFound, если подписка не найдена или уже удалена
+func DeleteWebhook(ctx context.Context, db *gorm.DB, id uint) (err error) {
+	ctx, span := startSpan(ctx, "services.DeleteWebhook", attribute.Int("webhook.id", int(id)))
+	defer func() { endSpan(span, err) }()
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deletedAt := time.Now()
+		res := tx.Model(&models.WebhookSubscription{}).
+			Where("id = ? AND deleted_at IS NULL", id).
+			Update("deleted_at", deletedAt)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+
+		if err := tx.Model(&models.WebhookEvent{}).
+			Where("subscription_id = ? AND status = ?", id, models.WebhookStatusPending).
+			Updates(map[string]any{"status": models.WebhookStatusFailed, "last_error": "subscription deleted"}).
+			Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, audit.ActionWebhookDelete, audit.EntityWebhook, webhookID(id),
+			map[string]any{"deleted_at": nil},
+			map[string]any{"deleted_at": deletedAt},
+		)
+	})
+}
+
+// ListWebhookEvents возвращает последние события подписки вместе с попытками доставки
+//
+// Параметры:
+//   - subscriptionID (uint): идентификатор подписки
+//   - limit (int): максимальное число событий
+//
+// Возвращает:
+//   - []models.WebhookEvent: события, начиная с самых новых
+//   - error: ошибку при выполнении запроса
+func ListWebhookEvents(ctx context.Context, db *gorm.DB, subscriptionID uint, limit int) (_ []models.WebhookEvent, err error) {
+	ctx, span := startSpan(ctx, "services.ListWebhookEvents", attribute.Int("webhook.id", int(subscriptionID)))
+	defer func() { endSpan(span, err) }()
+
+	var events []models.WebhookEvent
+	err = db.WithContext(ctx).
+		Preload("Deliveries", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+		Where("subscription_id = ?", subscriptionID).
+		Order("id desc").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetWebhookEvent возвращает событие вебхука и его подписку (включая удалённую)
+//
+// Возвращает:
+//   - error: ErrWebhookEventNotFound, если событие не найдено
+func GetWebhookEvent(ctx context.Context, db *gorm.DB, id uint64) (_ *models.WebhookEvent, _ *models.WebhookSubscription, err error) {
+	ctx, span := startSpan(ctx, "services.GetWebhookEvent", attribute.Int64("webhook_event.id", int64(id)))
+	defer func() { endSpan(span, err) }()
+
+	var event models.WebhookEvent
+	if err = db.WithContext(ctx).First(&event, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrWebhookEventNotFound
+		}
+		return nil, nil, err
+	}
+
+	var sub models.WebhookSubscription
+	if err = db.WithContext(ctx).First(&sub, event.SubscriptionID).Error; err != nil {
+		return nil, nil, err
+	}
+	return &event, &sub, nil
+}
+
+// webhookPayload - тело запроса вебхука о переводе
+type webhookPayload struct {
+	Type        string             `json:"type"`
+	Wallet      string             `json:"wallet"`
+	Transaction webhookTransaction `json:"transaction"`
+}
+
+// webhookTransaction - перевод в теле запроса вебхука; сумма в у.е.
+type webhookTransaction struct {
+	ID        uint              `json:"id"`
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	Amount    float64           `json:"amount"`
+	CreatedAt time.Time         `json:"created_at"`
+	Type      string            `json:"type"`
+	Status    string            `json:"status"`
+	Memo      string            `json:"memo,omitempty"`
+	Reference string            `json:"reference,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// enqueueTransferWebhooks добавляет в outbox события о переводе для подписок отправителя и получателя
+//
+// Вызывается внутри транзакции перевода, поэтому события фиксируются вместе с ним
+func enqueueTransferWebhooks(tx *gorm.DB, transaction *models.Transaction) error {
+	var subs []models.WebhookSubscription
+	if err := tx.Where("wallet_address IN ? AND deleted_at IS NULL", []string{transaction.From, transaction.To}).
+		Find(&subs).Error; err != nil {
+		return err
+	}
+
+	var events []models.WebhookEvent
+	for i := range subs {
+		eventType := models.WebhookEventTransferIncoming
+		if subs[i].WalletAddress == transaction.From {
+			eventType = models.WebhookEventTransferOutgoing
+		}
+		if !subs[i].Subscribes(eventType) {
+			continue
+		}
+
+		payload, err := json.Marshal(webhookPayload{
+			Type:   eventType,
+			Wallet: subs[i].WalletAddress,
+			Transaction: webhookTransaction{
+				ID:        transaction.ID,
+				From:      transaction.From,
+				To:        transaction.To,
+				Amount:    float64(transaction.Amount) / 100,
+				CreatedAt: transaction.CreatedAt,
+				Type:      transaction.Type,
+				Status:    transaction.Status,
+				Memo:      transaction.Memo,
+				Reference: transaction.Reference,
+				Metadata:  transaction.Metadata,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		events = append(events, models.WebhookEvent{
+			SubscriptionID: subs[i].ID,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         models.WebhookStatusPending,
+			NextAttemptAt:  transaction.CreatedAt,
+		})
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Create(&events).Error
+}
+
+// validateWebhookURL проверяет, что адрес получателя является абсолютным http(s) URL,
+// а его хост указывает только на публичные адреса
+func validateWebhookURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+	if err := webhooks.CheckHost(ctx, u.Hostname()); err != nil {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// generateWebhookSecret создаёт случайный секрет подписи вебхуков
+func generateWebhookSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand: " + err.Error())
+	}
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// webhookID возвращает идентификатор подписки для журнала аудита
+func webhookID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// webhookState возвращает состояние подписки для журнала аудита (без секрета)
+func webhookState(sub *models.WebhookSubscription) map[string]any {
+	return map[string]any{
+		"wallet":      sub.WalletAddress,
+		"url":         sub.URL,
+		"event_types": sub.EventTypes,
+	}
+}
@@ -4,10 +4,13 @@ package storage
 import (
 	"fmt"
 	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/metrics"
 	"github.com/normalniydada/test_task_infotecs/internal/models"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	otelgorm "gorm.io/plugin/opentelemetry/tracing"
+	"time"
 )
 
 // InitDB устанавливает соединение с базой данных PostgreSQL, выполняет миграции и возвращает объект GORM
@@ -20,30 +23,51 @@ import (
 //   - *gorm.DB: объект подключения к базе данных
 //
 // Возможные ошибки:
-//   - Завершает работу приложения (`zLog.Fatal`), если не удалось подключиться к базе данных
+//   - Завершает работу приложения (`zLog.Fatal`), если не удалось подключиться к базе данных после всех попыток
 //   - Завершает работу приложения, если произошла ошибка при миграции таблиц
 //
 // Логика работы:
-//  1. Формирование строки подключения DSN к базе данных.
-//  2. Открытие соединения с базой данных через GORM
-//  3. Выполнение автоматические миграции (`AutoMigrate`) для таблиц `Wallet` и `Transaction`
-//  4. Логирование успешного подключение и миграции
+//  1. Формирование строки подключения DSN к базе данных (с таймаутами подключения и выполнения запросов)
+//  2. Открытие соединения с базой данных через GORM с повторными попытками и экспоненциальной задержкой
+//  3. Подключение плагина трассировки SQL-запросов
+//  4. Настройка пула соединений `database/sql` и регистрация его метрик
+//  5. Выполнение автоматические миграции (`AutoMigrate`) для всех моделей и защита журнала аудита от изменений
+//  6. Логирование успешного подключение и миграции
 func InitDB(cfg *config.DatabaseConfig, zLog *zap.Logger) *gorm.DB {
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSlMode)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := connect(cfg, zLog)
 	if err != nil {
 		zLog.Fatal("Database connection error: ", zap.Error(err))
 	}
 
+	// Спаны OpenTelemetry для SQL-запросов (значения параметров не записываются)
+	if err = db.Use(otelgorm.NewPlugin(otelgorm.WithoutQueryVariables(), otelgorm.WithoutMetrics())); err != nil {
+		zLog.Fatal("Database tracing plugin error: ", zap.Error(err))
+	}
+
 	zLog.Info("Database connection success",
 		zap.String("host", cfg.Host),
 		zap.Int("port", cfg.Port),
 	)
 
+	// Настройка пула соединений
+	pdb, err := db.DB()
+	if err != nil {
+		zLog.Fatal("Error getting database: ", zap.Error(err))
+	}
+	pdb.SetMaxOpenConns(cfg.MaxOpenConns)
+	pdb.SetMaxIdleConns(cfg.MaxIdleConns)
+	pdb.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	pdb.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	metrics.RegisterDBStats(pdb)
+
 	// Миграция
-	if err = db.AutoMigrate(&models.Wallet{}, &models.Transaction{}); err != nil {
+	if err = db.AutoMigrate(Models()...); err != nil {
+		zLog.Fatal("Database migration error: ", zap.Error(err))
+	}
+	if err = protectAuditLog(db); err != nil {
+		zLog.Fatal("Database migration error: ", zap.Error(err))
+	}
+	if err = createTransactionReferenceIndex(db); err != nil {
 		zLog.Fatal("Database migration error: ", zap.Error(err))
 	}
 	zLog.Info("Database migration success")
@@ -51,6 +75,82 @@ func InitDB(cfg *config.DatabaseConfig, zLog *zap.Logger) *gorm.DB {
 	return db
 }
 
+// Models возвращает список моделей, таблицы которых создаются миграцией
+func Models() []any {
+	return []any{
+		&models.Wallet{}, &models.Transaction{}, &models.APIKey{}, &models.APIKeyWallet{},
+		&models.RateLimitBucket{}, &models.AuditLog{},
+		&models.WebhookSubscription{}, &models.WebhookEvent{}, &models.WebhookDelivery{},
+		&models.Payment{},
+	}
+}
+
+// protectAuditLog создаёт триггер, запрещающий изменение и удаление записей журнала аудита
+//
+// Триггер срабатывает для любых клиентов базы данных, а не только для API
+func protectAuditLog(db *gorm.DB) error {
+	return db.Exec(`
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+CREATE TRIGGER audit_logs_append_only
+	BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+`).Error
+}
+
+// createTransactionReferenceIndex создаёт уникальный индекс внешних идентификаторов переводов в пределах
+// кошелька отправителя
+//
+// Индекс частичный: переводы без идентификатора и отклонённые попытки не участвуют, поэтому попытку
+// можно повторить с тем же идентификатором. Индекс используется и для поиска по идентификатору
+func createTransactionReferenceIndex(db *gorm.DB) error {
+	return db.Exec(`
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transaction_reference
+	ON transactions ("from", reference)
+	WHERE reference <> '' AND status <> 'failed';
+`).Error
+}
+
+// connect открывает соединение с базой данных, повторяя попытки, пока PostgreSQL не станет доступен
+//
+// Задержка между попытками начинается с `RetryBackoff` и удваивается после каждой неудачи,
+// но не превышает `RetryMaxBackoff`. Всего выполняется `ConnectRetries + 1` попыток
+func connect(cfg *config.DatabaseConfig, zLog *zap.Logger) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s connect_timeout=%d",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSlMode, int(cfg.ConnectTimeout.Seconds()))
+	if cfg.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", cfg.StatementTimeout.Milliseconds())
+	}
+
+	backoff := cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			return nil, err
+		}
+
+		zLog.Warn("Database is not available, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > cfg.RetryMaxBackoff {
+			backoff = cfg.RetryMaxBackoff
+		}
+	}
+}
+
 // CloseDB закрывает соединение с базой данных
 //
 // Параметры:
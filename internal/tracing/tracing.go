@@ -0,0 +1,101 @@
+// Package tracing отвечает за настройку OpenTelemetry: экспорт спанов и распространение W3C trace context
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"os"
+)
+
+// Типы экспортёров спанов
+const (
+	ExporterOTLP = "otlp" // Отправка по протоколу OTLP/HTTP в коллектор
+	ExporterFile = "file" // Запись в локальный файл в формате JSON
+)
+
+// Init настраивает глобальный TracerProvider и W3C-пропагатор
+//
+// Параметры:
+//   - cfg (*config.TracingConfig): настройки трассировки
+//
+// Возвращает:
+//   - func(context.Context) error: функцию, отправляющую накопленные спаны и освобождающую ресурсы экспортёра
+//   - error: ошибку создания экспортёра
+//
+// Логика работы:
+//  1. Установка пропагатора W3C Trace Context и Baggage (используется всегда, даже при выключенной трассировке)
+//  2. Если трассировка выключена, возвращается пустая функция остановки
+//  3. Создание экспортёра OTLP или файлового экспортёра
+//  4. Регистрация TracerProvider с семплированием по `sample_ratio` с учётом решения родительского спана
+func Init(cfg *config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closeOutput, err := newExporter(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("create trace resource: %w", err), closeOutput())
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	// Файл экспортёра закрывается после отправки накопленных спанов
+	return func(ctx context.Context) error {
+		return errors.Join(provider.Shutdown(ctx), closeOutput())
+	}, nil
+}
+
+// newExporter создаёт экспортёр спанов в соответствии с настройкой `tracing.exporter`
+//
+// Вместе с экспортёром возвращается функция, закрывающая его файл; для OTLP она ничего не делает
+func newExporter(cfg *config.TracingConfig) (sdktrace.SpanExporter, func() error, error) {
+	noop := func() error { return nil }
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(context.Background(), opts...)
+		return exporter, noop, err
+	case ExporterFile:
+		f, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			return nil, nil, errors.Join(err, f.Close())
+		}
+		return exporter, f.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown exporter %q", cfg.Exporter)
+	}
+}
@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// ErrForbiddenAddress - адрес получателя находится во внутренней сети
+var ErrForbiddenAddress = errors.New("webhook target address is not public")
+
+// nonPublicPrefixes - диапазоны, не входящие в публичный интернет, помимо
+// проверяемых методами netip.Addr (loopback, private, link-local, multicast)
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // Текущая сеть
+	netip.MustParsePrefix("100.64.0.0/10"),  // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // Служебные адреса IETF
+	netip.MustParsePrefix("198.18.0.0/15"),  // Сети для тестирования производительности
+	netip.MustParsePrefix("240.0.0.0/4"),    // Зарезервированные и broadcast
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Локальная трансляция NAT64
+}
+
+// IsPublicAddr сообщает, можно ли доставлять вебхуки на адрес ip
+//
+// Запрещены loopback, частные, link-local, multicast, неуказанные и зарезервированные адреса;
+// IPv4-адреса, вложенные в IPv6 (::ffff:a.b.c.d), проверяются как IPv4
+func IsPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckHost проверяет, что host (IP-адрес или доменное имя) указывает только на публичные адреса
+//
+// Доменное имя разрешается через DNS; если хотя бы один из адресов непубличный или имя
+// не разрешается, возвращается ErrForbiddenAddress. Проверка при создании подписки не
+// защищает от смены DNS-записи, поэтому доставка дополнительно проверяет адрес при соединении
+func CheckHost(ctx context.Context, host string) error {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !IsPublicAddr(ip) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(ips) == 0 {
+		return ErrForbiddenAddress
+	}
+	for _, ip := range ips {
+		if !IsPublicAddr(ip) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// dialControl отклоняет соединение с непубличным адресом
+//
+// Вызывается net.Dialer после разрешения имени, непосредственно перед соединением, поэтому
+// получатель не может обойти проверку подменой DNS-записи после создания подписки
+func dialControl(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
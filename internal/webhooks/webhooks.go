@@ -0,0 +1,293 @@
+// Package webhooks доставляет события из transactional outbox получателям вебхуков
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/normalniydada/test_task_infotecs/internal/audit"
+	"github.com/normalniydada/test_task_infotecs/internal/config"
+	"github.com/normalniydada/test_task_infotecs/internal/metrics"
+	"github.com/normalniydada/test_task_infotecs/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Заголовки запроса вебхука
+const (
+	HeaderEventID   = "X-Webhook-ID"        // Идентификатор события, одинаковый для всех попыток
+	HeaderEventType = "X-Webhook-Event"     // Тип события
+	HeaderSignature = "X-Webhook-Signature" // Подпись: "t=<unix-время>,v1=<hex HMAC-SHA256>"
+)
+
+// maxErrorLength - максимальная длина сохраняемого описания ошибки доставки
+const maxErrorLength = 1024
+
+// Dispatcher доставляет события вебхуков с повторными попытками
+//
+// События выбираются из таблицы outbox с блокировкой `FOR UPDATE SKIP LOCKED` и сразу
+// откладываются на время доставки, поэтому несколько экземпляров сервиса не доставляют
+// одно событие одновременно. Доставка выполняется по принципу at-least-once:
+// получатель должен игнорировать повторы по заголовку X-Webhook-ID
+type Dispatcher struct {
+	db     *gorm.DB
+	cfg    *config.WebhooksConfig
+	client *http.Client
+}
+
+// NewDispatcher создаёт доставщик вебхуков
+//
+// HTTP-клиент соединяется только с публичными адресами (проверка выполняется после разрешения
+// имени) и не следует редиректам, чтобы получатель не мог направить запрос во внутреннюю сеть
+func NewDispatcher(db *gorm.DB, cfg *config.WebhooksConfig) *Dispatcher {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: cfg.Timeout, Control: dialControl}).DialContext
+	return &Dispatcher{
+		db:  db,
+		cfg: cfg,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Run периодически доставляет события, время попытки которых наступило
+//
+// Предназначена для запуска фоновой задачей; завершается после отмены ctx
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.dispatchDue(ctx); err != nil && ctx.Err() == nil {
+				zap.L().Warn("Webhook dispatch failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// dispatchDue выбирает пакет событий, ожидающих доставки, и доставляет их параллельно
+func (d *Dispatcher) dispatchDue(ctx context.Context) error {
+	var events []models.WebhookEvent
+	// Событие откладывается на время доставки, чтобы его не выбрал другой экземпляр;
+	// если экземпляр остановится во время доставки, событие будет выбрано повторно
+	lease := 2 * d.cfg.Timeout
+	err := d.db.WithContext(ctx).Raw(`
+UPDATE webhook_events SET next_attempt_at = now() + make_interval(secs => ?)
+WHERE id IN (
+	SELECT id FROM webhook_events
+	WHERE status = ? AND next_attempt_at <= now()
+	ORDER BY next_attempt_at
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *`, lease.Seconds(), models.WebhookStatusPending, d.cfg.BatchSize).
+		Scan(&events).Error
+	if err != nil || len(events) == 0 {
+		return err
+	}
+
+	ids := make([]uint, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.SubscriptionID)
+	}
+	var subs []models.WebhookSubscription
+	if err = d.db.WithContext(ctx).Where("id IN ?", ids).Find(&subs).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]*models.WebhookSubscription, len(subs))
+	for i := range subs {
+		byID[subs[i].ID] = &subs[i]
+	}
+
+	var wg sync.WaitGroup
+	for i := range events {
+		wg.Add(1)
+		go func(event *models.WebhookEvent) {
+			defer wg.Done()
+			if err := d.attempt(ctx, event, byID[event.SubscriptionID]); err != nil && ctx.Err() == nil {
+				zap.L().Warn("Webhook delivery bookkeeping failed", zap.Uint64("event_id", event.ID), zap.Error(err))
+			}
+		}(&events[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// attempt выполняет автоматическую попытку доставки и планирует следующую при неудаче
+func (d *Dispatcher) attempt(ctx context.Context, event *models.WebhookEvent, sub *models.WebhookSubscription) error {
+	if sub == nil || sub.DeletedAt != nil {
+		return d.db.WithContext(ctx).Model(event).
+			Updates(map[string]any{"status": models.WebhookStatusFailed, "last_error": "subscription deleted"}).
+			Error
+	}
+
+	delivery := d.send(ctx, event, sub)
+	event.Attempts++
+
+	updates := map[string]any{"attempts": event.Attempts, "last_error": delivery.Error}
+	outcome := "retry"
+	switch {
+	case delivery.Error == "":
+		outcome = "delivered"
+		updates["status"] = models.WebhookStatusDelivered
+		updates["delivered_at"] = delivery.CreatedAt
+	case event.Attempts >= d.cfg.MaxAttempts:
+		outcome = "failed"
+		updates["status"] = models.WebhookStatusFailed
+	default:
+		updates["next_attempt_at"] = time.Now().Add(d.backoff(event.Attempts))
+	}
+	metrics.ObserveWebhookDelivery(outcome)
+
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(delivery).Error; err != nil {
+			return err
+		}
+		return tx.Model(event).Updates(updates).Error
+	})
+}
+
+// Redeliver немедленно выполняет ручную попытку доставки события, в том числе уже доставленного
+// или недоставленного после исчерпания попыток
+//
+// При успехе событие помечается доставленным; при неудаче расписание автоматических попыток не меняется
+//
+// Возвращает:
+//   - *models.WebhookDelivery: результат попытки
+//   - error: ошибку сохранения результата
+func (d *Dispatcher) Redeliver(ctx context.Context, event *models.WebhookEvent, sub *models.WebhookSubscription) (*models.WebhookDelivery, error) {
+	delivery := d.send(ctx, event, sub)
+	delivery.Manual = true
+	if delivery.Error == "" {
+		metrics.ObserveWebhookDelivery("delivered")
+	} else {
+		metrics.ObserveWebhookDelivery("manual_failed")
+	}
+
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(delivery).Error; err != nil {
+			return err
+		}
+		if delivery.Error == "" {
+			if err := tx.Model(event).Updates(map[string]any{
+				"status":       models.WebhookStatusDelivered,
+				"delivered_at": delivery.CreatedAt,
+				"last_error":   "",
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return audit.Record(tx, audit.ActionWebhookRedeliver, audit.EntityWebhookEvent, strconv.FormatUint(event.ID, 10),
+			nil,
+			map[string]any{"delivery_id": delivery.ID, "status_code": delivery.StatusCode, "error": delivery.Error},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// send отправляет событие получателю и возвращает несохранённую запись о попытке
+func (d *Dispatcher) send(ctx context.Context, event *models.WebhookEvent, sub *models.WebhookSubscription) *models.WebhookDelivery {
+	start := time.Now()
+	delivery := &models.WebhookDelivery{EventID: event.ID, CreatedAt: start}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(event.Payload))
+	if err != nil {
+		delivery.Error = truncate(err.Error())
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "wallet-webhooks/1")
+	req.Header.Set(HeaderEventID, strconv.FormatUint(event.ID, 10))
+	req.Header.Set(HeaderEventType, event.EventType)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, start, event.Payload))
+
+	resp, err := d.client.Do(req)
+	delivery.Duration = time.Since(start)
+	if err != nil {
+		delivery.Error = truncate(err.Error())
+		return delivery
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		delivery.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return delivery
+}
+
+// backoff возвращает задержку перед следующей попыткой после attempts неудачных:
+// retry_backoff, удваиваемый после каждой неудачи, но не больше retry_max_backoff
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.RetryBackoff
+	for i := 1; i < attempts && delay < d.cfg.RetryMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.RetryMaxBackoff)
+}
+
+// Sign вычисляет значение заголовка X-Webhook-Signature
+//
+// Подписывается строка "<unix-время>.<тело запроса>" алгоритмом HMAC-SHA256 с секретом подписки;
+// получатель повторяет вычисление и сравнивает результат, а по времени отклоняет устаревшие запросы
+func Sign(secret string, t time.Time, payload []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(payload)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись в формате Sign: значение "t=<unix-время>,v1=<hex>" должно совпадать с подписью
+// тела payload секретом secret, а время подписи - отличаться от now не больше чем на tolerance
+//
+// Используется для входящих запросов, подписанных по той же схеме, например уведомлений платёжного провайдера
+func Verify(secret, signature string, payload []byte, now time.Time, tolerance time.Duration) bool {
+	var ts string
+	for _, part := range strings.Split(signature, ",") {
+		if v, ok := strings.CutPrefix(part, "t="); ok {
+			ts = v
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	t := time.Unix(unix, 0)
+	if now.Sub(t) > tolerance || t.Sub(now) > tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, t, payload)))
+}
+
+// truncate обрезает описание ошибки до размера столбца
+func truncate(s string) string {
+	if len(s) > maxErrorLength {
+		return s[:maxErrorLength]
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+// Package workers управляет фоновыми задачами приложения и их корректной остановкой
+package workers
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sync"
+)
+
+// Group - набор фоновых задач, разделяющих общий контекст отмены
+//
+// Каждая задача запускается в отдельной горутине и должна завершиться
+// после отмены переданного ей контекста
+type Group struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running map[string]bool
+	zLog    *zap.Logger
+}
+
+// NewGroup создаёт пустую группу фоновых задач
+//
+// Параметры:
+//   - zLog (*zap.Logger): логгер для записи событий запуска и остановки задач
+func NewGroup(zLog *zap.Logger) *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{
+		ctx:     ctx,
+		cancel:  cancel,
+		running: make(map[string]bool),
+		zLog:    zLog,
+	}
+}
+
+// Go запускает фоновую задачу с указанным именем
+//
+// Параметры:
+//   - name (string): имя задачи, используемое в логах и проверках готовности
+//   - fn (func(ctx context.Context)): тело задачи; должно вернуть управление после отмены ctx
+func (g *Group) Go(name string, fn func(ctx context.Context)) {
+	g.mu.Lock()
+	g.running[name] = true
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			g.mu.Lock()
+			g.running[name] = false
+			g.mu.Unlock()
+			g.zLog.Info("Background worker stopped", zap.String("worker", name))
+		}()
+
+		g.zLog.Info("Background worker started", zap.String("worker", name))
+		fn(g.ctx)
+	}()
+}
+
+// Running возвращает состояние всех зарегистрированных задач: true - задача выполняется
+func (g *Group) Running() map[string]bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state := make(map[string]bool, len(g.running))
+	for name, ok := range g.running {
+		state[name] = ok
+	}
+	return state
+}
+
+// Stop отменяет контекст всех задач и ожидает их завершения
+//
+// Параметры:
+//   - ctx (context.Context): ограничивает время ожидания завершения задач
+//
+// Возвращает:
+//   - error: ошибку контекста, если задачи не успели завершиться до его отмены
+func (g *Group) Stop(ctx context.Context) error {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
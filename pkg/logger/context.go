@@ -0,0 +1,64 @@
+// Package logger отвечает за инициализацию логгера Zap в зависимости от окружения (local/dev/prod)
+package logger
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"sync"
+)
+
+// ctxKey - ключ логгера запроса в контексте
+type ctxKey struct{}
+
+// scope хранит логгер запроса; поля могут дополняться по мере обработки запроса
+type scope struct {
+	mu   sync.Mutex
+	zLog *zap.Logger
+}
+
+// ToContext сохраняет логгер запроса в контексте
+//
+// Логгер дополняется полями `trace_id` и `span_id`, если в контексте есть активный спан
+func ToContext(ctx context.Context, zLog *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &scope{zLog: WithTrace(ctx, zLog)})
+}
+
+// FromContext возвращает логгер запроса из контекста
+//
+// Если логгер запроса не сохранён, возвращается глобальный логгер (`zap.L()`),
+// дополненный идентификаторами трассировки `trace_id` и `span_id`
+func FromContext(ctx context.Context) *zap.Logger {
+	if s, ok := ctx.Value(ctxKey{}).(*scope); ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.zLog
+	}
+	return WithTrace(ctx, zap.L())
+}
+
+// AddFields дополняет логгер запроса полями (например, адресами кошельков и результатом операции)
+//
+// Поля попадают во все последующие записи этого запроса, включая итоговую запись журнала доступа.
+// Если логгер запроса не сохранён в контексте, вызов ничего не делает
+func AddFields(ctx context.Context, fields ...zap.Field) {
+	if s, ok := ctx.Value(ctxKey{}).(*scope); ok {
+		s.mu.Lock()
+		s.zLog = s.zLog.With(fields...)
+		s.mu.Unlock()
+	}
+}
+
+// WithTrace дополняет логгер полями `trace_id` и `span_id` из спана в контексте
+//
+// Если контекст не содержит корректного спана, логгер возвращается без изменений
+func WithTrace(ctx context.Context, zLog *zap.Logger) *zap.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return zLog
+	}
+	return zLog.With(
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	)
+}
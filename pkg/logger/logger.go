@@ -2,11 +2,29 @@
 package logger
 
 import (
+	"fmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"os"
 )
 
+// Options содержит настройки логгера, переопределяющие значения по умолчанию для окружения
+//
+// Поля:
+//   - Level (string) — минимальный уровень логирования: debug, info, warn, error; пустая строка — уровень окружения
+//   - Sampling (*Sampling) — настройки семплирования; nil отключает семплирование
+type Options struct {
+	Level    string
+	Sampling *Sampling
+}
+
+// Sampling задаёт семплирование одинаковых записей за секунду:
+// первые Initial записей пишутся всегда, далее - каждая Thereafter-я
+type Sampling struct {
+	Initial    int
+	Thereafter int
+}
+
 // InitLogger инициализирует и возвращает логгер Zap в зависимости от переменной окружения `ENV`
 //
 // Поддерживаемые уровни логирования:
@@ -20,10 +38,45 @@ import (
 //
 // В случае ошибки инициализации вызывает `panic()`
 func InitLogger() *zap.Logger {
-	env := os.Getenv("ENV")
+	zapLog, err := envConfig().Build()
+	if err != nil {
+		panic("Error init logger: " + err.Error())
+	}
+
+	return zapLog
+}
 
+// New создаёт логгер Zap для окружения из `ENV` с уровнем и семплированием из opts
+//
+// Возвращает:
+//   - *zap.Logger: настроенный логгер Zap
+//   - error: ошибку, если уровень логирования некорректен или логгер не удалось создать
+func New(opts Options) (*zap.Logger, error) {
+	cfg := envConfig()
+
+	if opts.Level != "" {
+		level, err := zap.ParseAtomicLevel(opts.Level)
+		if err != nil {
+			return nil, fmt.Errorf("parse log level: %w", err)
+		}
+		cfg.Level = level
+	}
+
+	cfg.Sampling = nil
+	if opts.Sampling != nil {
+		cfg.Sampling = &zap.SamplingConfig{
+			Initial:    opts.Sampling.Initial,
+			Thereafter: opts.Sampling.Thereafter,
+		}
+	}
+
+	return cfg.Build()
+}
+
+// envConfig возвращает конфигурацию Zap для окружения из переменной `ENV`
+func envConfig() zap.Config {
 	var cfg zap.Config
-	switch env {
+	switch os.Getenv("ENV") {
 	case "local":
 		cfg = zap.NewDevelopmentConfig()
 		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
@@ -36,12 +89,5 @@ func InitLogger() *zap.Logger {
 		cfg = zap.NewDevelopmentConfig()
 		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
-
-	// Создание логгера
-	zapLog, err := cfg.Build()
-	if err != nil {
-		panic("Error init logger: " + err.Error())
-	}
-
-	return zapLog
+	return cfg
 }